@@ -0,0 +1,94 @@
+package config
+
+import (
+	RPC "hckvstore/rpc/raftrpc"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Encode把Op编码为protobuf，作为Raft日志条目的Data
+func (op Op) Encode() []byte {
+	data, _ := proto.Marshal(op.toProto())
+	return data
+}
+
+// DecodeOp从Raft日志条目的Data中解码出Op
+func DecodeOp(data []byte) (Op, error) {
+	var m RPC.Op
+	if err := proto.Unmarshal(data, &m); err != nil {
+		return Op{}, err
+	}
+	return opFromProto(&m), nil
+}
+
+func (op Op) toProto() *RPC.Op {
+	m := &RPC.Op{
+		Option:          op.Option,
+		Key:             op.Key,
+		Value:           op.Value,
+		Id:              op.Id,
+		Seq:             op.Seq,
+		Time:            op.Time,
+		Expected:        op.Expected,
+		ExpectedVersion: op.ExpectedVersion,
+		Lease:           op.Lease,
+		TTL:             op.TTL,
+		Revision:        op.Revision,
+		Group:           op.Group,
+		Servers:         op.Servers,
+		Shard:           op.Shard,
+		ConfigNum:       op.ConfigNum,
+	}
+	for _, c := range op.Compares {
+		m.Compares = append(m.Compares, &RPC.Compare{
+			Key:     c.Key,
+			Target:  c.Target,
+			Result:  c.Result,
+			Value:   c.Value,
+			Version: c.Version,
+		})
+	}
+	for _, sub := range op.Success {
+		m.Success = append(m.Success, sub.toProto())
+	}
+	for _, sub := range op.Failure {
+		m.Failure = append(m.Failure, sub.toProto())
+	}
+	return m
+}
+
+func opFromProto(m *RPC.Op) Op {
+	op := Op{
+		Option:          m.Option,
+		Key:             m.Key,
+		Value:           m.Value,
+		Id:              m.Id,
+		Seq:             m.Seq,
+		Time:            m.Time,
+		Expected:        m.Expected,
+		ExpectedVersion: m.ExpectedVersion,
+		Lease:           m.Lease,
+		TTL:             m.TTL,
+		Revision:        m.Revision,
+		Group:           m.Group,
+		Servers:         m.Servers,
+		Shard:           m.Shard,
+		ConfigNum:       m.ConfigNum,
+	}
+	for _, c := range m.Compares {
+		op.Compares = append(op.Compares, Compare{
+			Key:     c.Key,
+			Target:  c.Target,
+			Result:  c.Result,
+			Value:   c.Value,
+			Version: c.Version,
+		})
+	}
+	for _, sub := range m.Success {
+		op.Success = append(op.Success, opFromProto(sub))
+	}
+	for _, sub := range m.Failure {
+		op.Failure = append(op.Failure, opFromProto(sub))
+	}
+	return op
+}
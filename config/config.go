@@ -9,4 +9,32 @@ type Op struct {
 	Value  string
 	Id     int64
 	Seq    int64
+	Time   int64 // leader提交时的unix毫秒时间
+	// 条件写入比较的值或版本
+	Expected        string
+	ExpectedVersion int64
+	// Txn：所有Compares都成立时执行Success，否则执行Failure，整个Txn是一条日志
+	Compares []Compare
+	Success  []Op
+	Failure  []Op
+	// 写入的key关联的lease，或者lease操作的lease；TTL是LeaseGrant的有效期(毫秒)
+	Lease int64
+	TTL   int64
+	// Compact删除这个revision之前的版本
+	Revision int64
+	// shard controller的命令：Join加入的复制组和它的服务地址，Leave移除的组，Move移动的shard和目标组
+	Group   int64
+	Servers []string
+	Shard   int32
+	// 分片迁移：InstallShard、DeleteShard和ShardDeleted所属的配置编号
+	ConfigNum int64
+}
+
+// Compare是Txn的一个比较条件：Target为"Value"或"Version"，Result为"="、"!="、">"或"<"
+type Compare struct {
+	Key     string
+	Target  string
+	Result  string
+	Value   string
+	Version int64
 }
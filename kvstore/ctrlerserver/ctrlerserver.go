@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	config "hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	pst "hckvstore/persister"
+	raft "hckvstore/raft"
+	ctrlerproto "hckvstore/rpc/ctrlerrpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// 等待日志apply的最长时间，小于client的RPC超时
+const proposalTimeout = 3 * time.Second
+
+// CtrlerServer是shard controller的一个副本，配置的修改通过Raft复制
+type CtrlerServer struct {
+	raft   *raft.Raft
+	ctrler *shardctrler.Ctrler
+}
+
+func (cs *CtrlerServer) Join(ctx context.Context, args *ctrlerproto.JoinArgs) (*ctrlerproto.CtrlerReply, error) {
+	return cs.propose(ctx, config.Op{Option: shardctrler.OpJoin, Group: args.Group, Servers: args.Servers}), nil
+}
+
+func (cs *CtrlerServer) Leave(ctx context.Context, args *ctrlerproto.LeaveArgs) (*ctrlerproto.CtrlerReply, error) {
+	return cs.propose(ctx, config.Op{Option: shardctrler.OpLeave, Group: args.Group}), nil
+}
+
+func (cs *CtrlerServer) Move(ctx context.Context, args *ctrlerproto.MoveArgs) (*ctrlerproto.CtrlerReply, error) {
+	return cs.propose(ctx, config.Op{Option: shardctrler.OpMove, Shard: args.Shard, Group: args.Group}), nil
+}
+
+// 和KV的Get一样用ReadIndex保证读到所有已经提交的修改
+func (cs *CtrlerServer) Query(ctx context.Context, args *ctrlerproto.QueryArgs) (*ctrlerproto.QueryReply, error) {
+	queryReply := &ctrlerproto.QueryReply{}
+	if _, isLeader := cs.raft.ReadIndex(); !isLeader {
+		return queryReply, nil
+	}
+	queryReply.IsLeader = true
+	queryReply.Config = cs.ctrler.Query(args.Num).ToProto()
+	return queryReply, nil
+}
+
+func (cs *CtrlerServer) propose(ctx context.Context, op config.Op) *ctrlerproto.CtrlerReply {
+	reply := &ctrlerproto.CtrlerReply{}
+	op.Time = time.Now().UnixNano() / int64(time.Millisecond)
+	proposal, err := cs.raft.Propose(op)
+	if err != nil {
+		return reply
+	}
+	reply.IsLeader = true
+	ctx, cancel := context.WithTimeout(ctx, proposalTimeout)
+	defer cancel()
+	if err := proposal.Wait(ctx); err != nil {
+		if err == raft.ErrProposalDropped {
+			reply.IsLeader = false
+		}
+		reply.Err = err.Error()
+		return reply
+	}
+	result, _ := proposal.Result().(shardctrler.Reply)
+	reply.Success, reply.Err, reply.Num = true, result.Err, result.Num
+	return reply
+}
+
+func (cs *CtrlerServer) RegisterServer(address string) {
+	for {
+		lis, err := net.Listen("tcp", address)
+		fmt.Println(address)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		grpcServer := grpc.NewServer()
+		ctrlerproto.RegisterShardCtrlerServer(grpcServer, cs)
+		reflection.Register(grpcServer)
+		if err := grpcServer.Serve(lis); err != nil {
+			fmt.Println("failed to serve: ", err)
+		}
+	}
+}
+
+func main() {
+	var add = flag.String("address", "", "Input Your address")
+	var mems = flag.String("members", "", "Input Your follower")
+	var maxraftstate = flag.Int("maxraftstate", 1<<20, "Snapshot threshold of raft state in bytes, -1 to disable")
+	var clockdrift = flag.Int("clockdrift", 100, "Clock drift margin of leader lease in ms")
+	flag.Parse()
+	address := *add
+	members := strings.Split(*mems, ",")
+
+	persister := &pst.Persister{}
+	persister.Init("../db/" + address)
+	cs := &CtrlerServer{ctrler: shardctrler.MakeCtrler(persister)}
+	cs.raft = raft.MakeRaft(address, members, persister, &sync.Mutex{}, cs.ctrler, 0, *maxraftstate, time.Duration(*clockdrift)*time.Millisecond)
+	// 和kvserver相同，client连接Raft地址后面加1的端口
+	go cs.RegisterServer(address + "1")
+
+	select {}
+}
@@ -13,6 +13,7 @@ import (
         "sync/atomic"
         "time"
 
+        "hckvstore/kvstore/shardctrler"
         kvproto "hckvstore/rpc/kvrpc"
         "hckvstore/util"
 
@@ -25,6 +26,15 @@ type Clerk struct {
         id       int64
         leaderId int
         seq      int64
+        // Get是否使用leader lease读，false时每次Get都由leader发送心跳确认身份
+        leaseRead bool
+        // Get是否允许读任意节点(包括learner)的本地数据，可能读到旧值
+        staleRead bool
+        // 分片时缓存的配置，servers是当前请求的复制组group的服务地址，leaders记录每个组的leader
+        ctrler  *shardctrler.Clerk
+        config  shardctrler.Config
+        group   int64
+        leaders map[int64]int
 }
 
 func MakeId() int64 {
@@ -45,6 +55,22 @@ func MakeClerk(servers []string) *Clerk {
 }
 
 func (ck *Clerk) Get(key string) string {
+        return ck.get(&kvproto.GetArgs{Key: key}).Value
+}
+
+// GetVersion返回key的值和版本，版本可以用于CompareVersionAndSwap，key不存在时版本为0
+func (ck *Clerk) GetVersion(key string) (string, int64) {
+        reply := ck.get(&kvproto.GetArgs{Key: key})
+        return reply.Value, reply.Version
+}
+
+// GetAt返回key在revision时的值和版本，revision已经被压缩时返回错误信息
+func (ck *Clerk) GetAt(key string, revision int64) (string, int64, string) {
+        reply := ck.get(&kvproto.GetArgs{Key: key, Revision: revision})
+        return reply.Value, reply.Version, reply.Err
+}
+
+func (ck *Clerk) get(args *kvproto.GetArgs) *kvproto.GetReply {
         // getArgs := &kvproto.GetArgs{Key: key}
         // id := rand.Intn(len(ck.servers)+10) % len(ck.servers)
         // getReply, err := ck.GetValue(ck.servers[id], getArgs)
@@ -63,33 +89,71 @@ func (ck *Clerk) Get(key string) string {
         //      id = rand.Intn(len(ck.servers)+10) % len(ck.servers)
         //      util.DPrintf("id", id)
         // }
-        args := &kvproto.GetArgs{Key: key}
-        id := rand.Intn(len(ck.servers)+10) % len(ck.servers)
+        // 只有leader可以通过ReadIndex返回线性一致的结果，不存在的key返回""
+        args.LeaseRead, args.StaleRead = ck.leaseRead, ck.staleRead
+        ck.route(args.Key)
+        id := ck.leaderId
+        if ck.staleRead {
+                // stale read由任意一个能连上的节点返回，随机选择节点分摊读负载
+                id = rand.Intn(len(ck.servers))
+                for {
+                        reply, err := ck.GetValue(ck.servers[id], args)
+                        if err == nil && ck.wrongGroup(args.Key, reply.Err, &id) {
+                                continue
+                        }
+                        if err == nil {
+                                return reply
+                        }
+                        id = (id + 1) % len(ck.servers)
+                }
+        }
         for {
-                reply, _ := ck.GetValue(ck.servers[id], args)
-                if reply.Value != "" {
+                reply, err := ck.GetValue(ck.servers[id], args)
+                if err == nil && ck.wrongGroup(args.Key, reply.Err, &id) {
+                        continue
+                }
+                if err == nil && reply.IsLeader {
+                        ck.leaderId = id
                         util.DPrintf("server: %v", ck.servers[id])
-                        return reply.Value
+                        return reply
                 } else {
                         // fmt.Println("can not connect ", ck.servers[id], "or it's not leader")
                 }
-                id = rand.Intn(len(ck.servers)+10) % len(ck.servers)
+                id = (id + 1) % len(ck.servers)
         }
 
 }
 
 func (ck *Clerk) Put(key string, value string) bool {
+        return ck.PutWithLease(key, value, 0)
+}
+
+// 写入key并关联到lease，lease被撤销或者到期时key被删除。lease不存在时不写入，返回false
+func (ck *Clerk) PutWithLease(key string, value string, lease int64) bool {
         // You will have to modify this function.
-        args := &kvproto.PutAppendArgs{Key: key, Value: value, Op: "Put", Id: ck.id, Seq: ck.seq}
+        // 每个新请求使用新的Seq，重试时不变，服务端据此识别重复的请求
+        ck.seq++
+        args := &kvproto.PutAppendArgs{Key: key, Value: value, Op: "Put", Id: ck.id, Seq: ck.seq, Lease: lease}
+        ck.route(key)
         id := ck.leaderId
         for {
                 //fmt.Println(id)
                 reply, ok := ck.putAppendValue(ck.servers[id], args)
                 //fmt.Println(ok)
+                if ok && ck.wrongGroup(key, reply.Err, &id) {
+                        continue
+                }
 
-                if ok && reply.IsLeader {
+                if ok && reply.Success {
                         ck.leaderId = id
-                        return true
+                        if reply.Err != "" {
+                                util.DPrintf("put failed: %v", reply.Err)
+                        }
+                        return reply.Err == ""
+                } else if ok && reply.IsLeader {
+                        // leader没能在期限内确认结果，写入可能已经生效，重新发送给同一个leader
+                        util.DPrintf("put outcome unknown: %v", reply.Err)
+                        continue
                 } else {
                         fmt.Println(ok, "can not connect ", ck.servers[id], "or it's not leader")
                 }
@@ -99,18 +163,523 @@ func (ck *Clerk) Put(key string, value string) bool {
 
 func (ck *Clerk) Append(key string, value string) bool {
         // You will have to modify this function.
+        // 每个新请求使用新的Seq，重试时不变，服务端据此识别重复的请求
+        ck.seq++
         args := &kvproto.PutAppendArgs{Key: key, Value: value, Op: "Append", Id: ck.id, Seq: ck.seq}
+        ck.route(key)
         id := ck.leaderId
         for {
                 reply, ok := ck.putAppendValue(ck.servers[id], args)
-                if ok && reply.IsLeader {
+                if ok && ck.wrongGroup(key, reply.Err, &id) {
+                        continue
+                }
+                if ok && reply.Success {
+                        ck.leaderId = id
+                        return true
+                } else if ok && reply.IsLeader {
+                        util.DPrintf("append outcome unknown: %v", reply.Err)
+                        continue
+                }
+                id = (id + 1) % len(ck.servers)
+        }
+}
+
+// 删除key，删除不存在的key同样返回成功
+func (ck *Clerk) Delete(key string) bool {
+        ck.seq++
+        args := &kvproto.DeleteArgs{Key: key, Id: ck.id, Seq: ck.seq}
+        ck.route(key)
+        id := ck.leaderId
+        for {
+                reply, ok := ck.deleteValue(ck.servers[id], args)
+                if ok && ck.wrongGroup(key, reply.Err, &id) {
+                        continue
+                }
+                if ok && reply.Success {
+                        ck.leaderId = id
+                        return true
+                } else if ok && reply.IsLeader {
+                        util.DPrintf("delete outcome unknown: %v", reply.Err)
+                        continue
+                }
+                id = (id + 1) % len(ck.servers)
+        }
+}
+
+// 条件写入：条件成立时写入value并返回true，否则返回false和key当前的值(以及版本)
+func (ck *Clerk) CompareAndSwap(key string, expected string, value string) (bool, string) {
+        reply := ck.compareAndSwap(&kvproto.CASArgs{Key: key, Value: value, Cond: kvproto.CASCond_VALUE, Expected: expected})
+        return reply.Swapped, reply.Value
+}
+
+func (ck *Clerk) CompareVersionAndSwap(key string, version int64, value string) (bool, string, int64) {
+        reply := ck.compareAndSwap(&kvproto.CASArgs{Key: key, Value: value, Cond: kvproto.CASCond_VERSION, ExpectedVersion: version})
+        return reply.Swapped, reply.Value, reply.Version
+}
+
+func (ck *Clerk) PutIfAbsent(key string, value string) (bool, string) {
+        reply := ck.compareAndSwap(&kvproto.CASArgs{Key: key, Value: value, Cond: kvproto.CASCond_ABSENT})
+        return reply.Swapped, reply.Value
+}
+
+func (ck *Clerk) PutIfPresent(key string, value string) (bool, string) {
+        reply := ck.compareAndSwap(&kvproto.CASArgs{Key: key, Value: value, Cond: kvproto.CASCond_PRESENT})
+        return reply.Swapped, reply.Value
+}
+
+func (ck *Clerk) compareAndSwap(args *kvproto.CASArgs) *kvproto.CASReply {
+        ck.seq++
+        args.Id, args.Seq = ck.id, ck.seq
+        ck.route(args.Key)
+        id := ck.leaderId
+        for {
+                reply, ok := ck.casValue(ck.servers[id], args)
+                if ok && ck.wrongGroup(args.Key, reply.Err, &id) {
+                        continue
+                }
+                if ok && reply.Success {
                         ck.leaderId = id
+                        return reply
+                } else if ok && reply.IsLeader {
+                        util.DPrintf("compare and swap outcome unknown: %v", reply.Err)
+                        continue
+                }
+                id = (id + 1) % len(ck.servers)
+        }
+}
+
+// ScanIterator按页读取Scan的结果，当前页读完之后自动请求下一页。
+// 每一页来自服务端的同一个快照，不同的页之间可能有新的写入。
+// 分片时每个复制组只返回自己负责的key：Scan发给配置中的每一个组，按key的顺序合并各组的结果，
+// 配置在Scan的过程中变化时查询新的配置，从已经返回的最后一个key之后重新开始
+type ScanIterator struct {
+        ck      *Clerk
+        args    *kvproto.ScanArgs
+        parts   []*scanPart // 每个复制组一个，不分片时只有一个
+        cur     *scanPart   // 当前的key所在的组
+        last    string      // 已经返回的最后一个key
+        started bool        // 是否已经返回过key
+        err     string
+}
+
+// 一个复制组的Scan结果
+type scanPart struct {
+        group int64
+        args  *kvproto.ScanArgs
+        page  []*kvproto.KeyValue
+        pos   int
+        done  bool // 服务端没有返回NextToken，不再有下一页
+}
+
+// 每个组的revision是各自日志的下标，分片时不能按revision Scan所有的组
+const ErrScanRevision = "revision scan across replica groups"
+
+// Scan返回args描述的范围的迭代器，args.Limit为每一页的大小
+func (ck *Clerk) Scan(args *kvproto.ScanArgs) *ScanIterator {
+        args.LeaseRead, args.StaleRead = ck.leaseRead, ck.staleRead
+        args.Token = nil
+        it := &ScanIterator{ck: ck, args: args}
+        if ck.ctrler != nil && args.Revision > 0 {
+                it.err = ErrScanRevision
+                return it
+        }
+        it.reset()
+        return it
+}
+
+func (ck *Clerk) ScanPrefix(prefix string) *ScanIterator {
+        return ck.Scan(&kvproto.ScanArgs{Prefix: prefix})
+}
+
+// 按最新的配置为每个组重新开始Scan，跳过已经返回的key
+func (it *ScanIterator) reset() {
+        it.parts, it.cur = nil, nil
+        var token []byte
+        if it.started {
+                // 正序时token是下一页的第一个key，逆序时token是下一页的上界
+                if it.args.Reverse {
+                        token = []byte(it.last)
+                } else {
+                        token = []byte(it.last + "\x00")
+                }
+        }
+        if it.ck.ctrler == nil {
+                it.addPart(it.ck.group, 0, token)
+                return
+        }
+        it.ck.config = it.ck.ctrler.Query(0)
+        for gid := range it.ck.config.Groups {
+                it.addPart(gid, it.ck.config.Num, token)
+        }
+}
+
+func (it *ScanIterator) addPart(gid int64, num int64, token []byte) {
+        args := &kvproto.ScanArgs{
+                Start:     it.args.Start,
+                End:       it.args.End,
+                Prefix:    it.args.Prefix,
+                Limit:     it.args.Limit,
+                Reverse:   it.args.Reverse,
+                KeysOnly:  it.args.KeysOnly,
+                Token:     token,
+                LeaseRead: it.args.LeaseRead,
+                StaleRead: it.args.StaleRead,
+                Revision:  it.args.Revision,
+                Group:     gid,
+                ConfigNum: num,
+        }
+        it.parts = append(it.parts, &scanPart{group: gid, args: args})
+}
+
+// Next移动到下一个key，没有更多的key时返回false
+func (it *ScanIterator) Next() bool {
+        if it.cur != nil {
+                it.cur.pos++
+                it.cur = nil
+        }
+        for it.err == "" {
+                var next *scanPart
+                reset := false
+                for _, p := range it.parts {
+                        if !it.fill(p) {
+                                reset = true
+                                break
+                        }
+                        if it.err != "" {
+                                return false
+                        }
+                        if p.pos < len(p.page) && (next == nil || it.before(p.page[p.pos].Key, next.page[next.pos].Key)) {
+                                next = p
+                        }
+                }
+                if reset {
+                        it.reset()
+                        continue
+                }
+                if next == nil {
+                        return false
+                }
+                it.cur, it.last, it.started = next, next.page[next.pos].Key, true
+                return true
+        }
+        return false
+}
+
+// 按Scan的方向，key a是否在b之前
+func (it *ScanIterator) before(a, b string) bool {
+        if it.args.Reverse {
+                return a > b
+        }
+        return a < b
+}
+
+// 读取p的下一页，直到p还有没有返回的key或者已经读完。
+// 返回false说明组已经apply了更新的配置，需要按新的配置重新开始
+func (it *ScanIterator) fill(p *scanPart) bool {
+        for p.pos >= len(p.page) && !p.done {
+                reply := it.ck.scanGroup(p.group, p.args)
+                switch {
+                case reply.Err == shardctrler.ErrShardNotReady:
+                        // 组还没有apply这个配置，或者还有shard正在迁移到这个组
+                        time.Sleep(100 * time.Millisecond)
+                        continue
+                case reply.Err == shardctrler.ErrWrongGroup && it.ck.ctrler != nil:
+                        return false
+                case reply.Err != "":
+                        p.page, p.done, it.err = nil, true, reply.Err
                         return true
                 }
+                p.page, p.pos = reply.Kvs, 0
+                p.args.Token = reply.NextToken
+                p.done = len(reply.NextToken) == 0
+        }
+        return true
+}
+
+func (it *ScanIterator) Key() string {
+        return it.cur.page[it.cur.pos].Key
+}
+
+func (it *ScanIterator) Value() string {
+        return it.cur.page[it.cur.pos].Value
+}
+
+func (it *ScanIterator) Version() int64 {
+        return it.cur.page[it.cur.pos].Version
+}
+
+// Err返回服务端拒绝Scan的原因，例如args.Revision已经被压缩
+func (it *ScanIterator) Err() string {
+        return it.err
+}
+
+// 在复制组gid上读一页，不分片时gid就是当前的组
+func (ck *Clerk) scanGroup(gid int64, args *kvproto.ScanArgs) *kvproto.ScanReply {
+        if ck.ctrler != nil && !ck.setGroup(gid) {
+                // 其他请求更新了缓存的配置，这个组已经不在配置中
+                return &kvproto.ScanReply{Err: shardctrler.ErrWrongGroup}
+        }
+        return ck.scanPage(args)
+}
+
+func (ck *Clerk) scanPage(args *kvproto.ScanArgs) *kvproto.ScanReply {
+        id := ck.leaderId
+        if ck.staleRead {
+                id = rand.Intn(len(ck.servers))
+        }
+        for {
+                reply, err := ck.scanValue(ck.servers[id], args)
+                if err == nil && (reply.IsLeader || ck.staleRead) {
+                        if !ck.staleRead {
+                                ck.leaderId = id
+                        }
+                        return reply
+                }
+                id = (id + 1) % len(ck.servers)
+        }
+}
+
+// 删除revision之前的历史版本，返回压缩之后能读到的最早的revision
+func (ck *Clerk) Compact(revision int64) (int64, string) {
+        ck.seq++
+        args := &kvproto.CompactArgs{Revision: revision, Id: ck.id, Seq: ck.seq, Group: ck.group}
+        id := ck.leaderId
+        for {
+                reply, ok := ck.compactValue(ck.servers[id], args)
+                if ok && reply.Success {
+                        ck.leaderId = id
+                        return reply.Revision, reply.Err
+                } else if ok && reply.IsLeader {
+                        util.DPrintf("compact outcome unknown: %v", reply.Err)
+                        continue
+                }
                 id = (id + 1) % len(ck.servers)
         }
 }
 
+// 授予一个有效期为ttl的lease，返回lease的ID
+func (ck *Clerk) LeaseGrant(ttl time.Duration) int64 {
+        reply := ck.leaseOp("LeaseGrant", &kvproto.LeaseArgs{}, int64(ttl/time.Second))
+        return reply.Lease
+}
+
+// 续约lease，返回false说明lease已经到期或者被撤销
+func (ck *Clerk) LeaseKeepAlive(lease int64) bool {
+        reply := ck.leaseOp("LeaseKeepAlive", &kvproto.LeaseArgs{Lease: lease}, 0)
+        return reply.Err == ""
+}
+
+// 撤销lease并删除所有关联的key
+func (ck *Clerk) LeaseRevoke(lease int64) bool {
+        reply := ck.leaseOp("LeaseRevoke", &kvproto.LeaseArgs{Lease: lease}, 0)
+        return reply.Err == ""
+}
+
+// KeepAlive每隔ttl/3续约一次lease，直到ctx结束或者lease已经不存在
+func (ck *Clerk) KeepAlive(ctx context.Context, lease int64, ttl time.Duration) {
+        for {
+                select {
+                case <-ctx.Done():
+                        return
+                case <-time.After(ttl / 3):
+                }
+                if !ck.LeaseKeepAlive(lease) {
+                        return
+                }
+        }
+}
+
+func (ck *Clerk) leaseOp(op string, args *kvproto.LeaseArgs, ttl int64) *kvproto.LeaseReply {
+        ck.seq++
+        args.Id, args.Seq, args.Group = ck.id, ck.seq, ck.group
+        id := ck.leaderId
+        for {
+                reply, ok := ck.leaseValue(ck.servers[id], op, args, ttl)
+                if ok && reply.Success {
+                        ck.leaderId = id
+                        if reply.Err != "" {
+                                util.DPrintf("%v failed: %v", op, reply.Err)
+                        }
+                        return reply
+                } else if ok && reply.IsLeader {
+                        util.DPrintf("%v outcome unknown: %v", op, reply.Err)
+                        continue
+                }
+                id = (id + 1) % len(ck.servers)
+        }
+}
+
+// 前缀下的key分布在多个复制组中，每个组的revision是各自日志的下标，分片时不能只监听一个组
+const ErrWatchPrefix = "prefix watch across replica groups"
+
+// Watch监听key(prefix为true时监听以key开头的所有key)的修改，startRevision为0时只监听之后的修改。
+// 连接断开或者被服务端取消时换一个节点从下一个revision继续，不会丢失或者重复事件。
+// 返回的channel在ctx结束或者revision已经被压缩时关闭，后一种情况最后一个响应带有CompactRevision。
+// 分片时不支持前缀Watch，返回ErrWatchPrefix
+func (ck *Clerk) Watch(ctx context.Context, key string, prefix bool, startRevision int64) (<-chan *kvproto.WatchResponse, string) {
+        if ck.ctrler != nil && prefix {
+                return nil, ErrWatchPrefix
+        }
+        ch := make(chan *kvproto.WatchResponse)
+        if !prefix {
+                ck.route(key)
+        }
+        servers, group := ck.servers, ck.group
+        go func() {
+                defer close(ch)
+                next := startRevision
+                id := rand.Intn(len(servers))
+                for ctx.Err() == nil {
+                        args := &kvproto.WatchArgs{Key: key, Prefix: prefix, StartRevision: next, Group: group}
+                        if ck.watchStream(ctx, servers[id], args, ch, &next) {
+                                return
+                        }
+                        id = (id + 1) % len(servers)
+                        time.Sleep(time.Millisecond * 100)
+                }
+        }()
+        return ch, ""
+}
+
+// 从address接收事件直到连接断开，next记录下一个需要的revision。revision已经被压缩时返回true
+func (ck *Clerk) watchStream(ctx context.Context, address string, args *kvproto.WatchArgs, ch chan<- *kvproto.WatchResponse, next *int64) bool {
+        conn, err := grpc.Dial(address, grpc.WithInsecure())
+        if err != nil {
+                log.Printf("watchStream() did not connect: %v", err)
+                return false
+        }
+        defer conn.Close()
+        ctx, cancel := context.WithCancel(ctx)
+        defer cancel()
+        stream, err := kvproto.NewKVClient(conn).Watch(ctx, args)
+        if err != nil {
+                log.Println("watchStream() is failed", err)
+                return false
+        }
+        for {
+                resp, err := stream.Recv()
+                if err != nil {
+                        return false
+                }
+                switch {
+                case resp.Created:
+                        if *next == 0 {
+                                *next = resp.Revision + 1
+                        }
+                case resp.CompactRevision != 0:
+                        select {
+                        case ch <- resp:
+                        case <-ctx.Done():
+                        }
+                        return true
+                case resp.Canceled:
+                        return false
+                default:
+                        select {
+                        case ch <- resp:
+                                *next = resp.Revision + 1
+                        case <-ctx.Done():
+                                return false
+                        }
+                }
+        }
+}
+
+// 多个key的原子事务：compares都成立时执行success，否则执行failure。
+// 返回执行的是否是success，以及每个执行的操作的结果
+func (ck *Clerk) Txn(compares []*kvproto.Compare, success []*kvproto.TxnOp, failure []*kvproto.TxnOp) (bool, []*kvproto.TxnResult) {
+        ck.seq++
+        args := &kvproto.TxnArgs{Compares: compares, Success: success, Failure: failure, Id: ck.id, Seq: ck.seq}
+        var keys []string
+        for _, c := range compares {
+                keys = append(keys, c.Key)
+        }
+        for _, op := range append(append([]*kvproto.TxnOp{}, success...), failure...) {
+                keys = append(keys, op.Key)
+        }
+        if len(keys) > 0 {
+                ck.route(keys[0])
+        }
+        id := ck.leaderId
+        for {
+                if !ck.sameGroup(keys) {
+                        util.DPrintf("txn failed: keys belong to different groups")
+                        return false, nil
+                }
+                reply, ok := ck.txnValue(ck.servers[id], args)
+                if ok && len(keys) > 0 && ck.wrongGroup(keys[0], reply.Err, &id) {
+                        continue
+                }
+                if ok && reply.Success {
+                        ck.leaderId = id
+                        if reply.Err != "" {
+                                util.DPrintf("txn failed: %v", reply.Err)
+                        }
+                        return reply.Succeeded, reply.Results
+                } else if ok && reply.IsLeader {
+                        util.DPrintf("txn outcome unknown: %v", reply.Err)
+                        continue
+                }
+                id = (id + 1) % len(ck.servers)
+        }
+}
+
+// 管理接口：address为Raft地址，请求会一直重试直到发送给leader
+func (ck *Clerk) AddServer(address string) (bool, string) {
+        return ck.changeMembership("AddServer", address)
+}
+
+func (ck *Clerk) RemoveServer(address string) (bool, string) {
+        return ck.changeMembership("RemoveServer", address)
+}
+
+// 把领导权转移给address对应的Raft节点
+func (ck *Clerk) TransferLeadership(address string) (bool, string) {
+        return ck.changeMembership("TransferLeadership", address)
+}
+
+func (ck *Clerk) AddLearner(address string) (bool, string) {
+        return ck.changeMembership("AddLearner", address)
+}
+
+func (ck *Clerk) PromoteLearner(address string) (bool, string) {
+        return ck.changeMembership("PromoteLearner", address)
+}
+
+func (ck *Clerk) changeMembership(op string, address string) (bool, string) {
+        args := &kvproto.MembershipArgs{Address: address, Group: ck.group}
+        id := ck.leaderId
+        for {
+                conn, err := grpc.Dial(ck.servers[id], grpc.WithInsecure())
+                if err == nil {
+                        client := kvproto.NewKVClient(conn)
+                        // 新节点追赶日志可能需要一段时间
+                        ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+                        var reply *kvproto.MembershipReply
+                        switch op {
+                        case "AddServer":
+                                reply, err = client.AddServer(ctx, args)
+                        case "RemoveServer":
+                                reply, err = client.RemoveServer(ctx, args)
+                        case "AddLearner":
+                                reply, err = client.AddLearner(ctx, args)
+                        case "PromoteLearner":
+                                reply, err = client.PromoteLearner(ctx, args)
+                        default:
+                                reply, err = client.TransferLeadership(ctx, args)
+                        }
+                        cancel()
+                        conn.Close()
+                        if err == nil && reply.IsLeader {
+                                ck.leaderId = id
+                                return reply.Success, reply.Err
+                        }
+                }
+                id = (id + 1) % len(ck.servers)
+                time.Sleep(time.Millisecond * 100)
+        }
+}
+
 func (ck *Clerk) putAppendValue(address string, args *kvproto.PutAppendArgs) (*kvproto.PutAppendReply, bool) {
         // Initialize Client
         conn, err := grpc.Dial(address, grpc.WithInsecure()) //,grpc.WithBlock())
@@ -131,6 +700,122 @@ func (ck *Clerk) putAppendValue(address string, args *kvproto.PutAppendArgs) (*k
         return reply, true
 }
 
+func (ck *Clerk) deleteValue(address string, args *kvproto.DeleteArgs) (*kvproto.DeleteReply, bool) {
+        conn, err := grpc.Dial(address, grpc.WithInsecure())
+        if err != nil {
+                log.Printf("deleteValue() did not connect: %v", err)
+                return nil, false
+        }
+        defer conn.Close()
+        client := kvproto.NewKVClient(conn)
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+        defer cancel()
+        reply, err := client.Delete(ctx, args)
+        if err != nil {
+                log.Println("deleteValue() is failed", err)
+                return nil, false
+        }
+        return reply, true
+}
+
+func (ck *Clerk) casValue(address string, args *kvproto.CASArgs) (*kvproto.CASReply, bool) {
+        conn, err := grpc.Dial(address, grpc.WithInsecure())
+        if err != nil {
+                log.Printf("casValue() did not connect: %v", err)
+                return nil, false
+        }
+        defer conn.Close()
+        client := kvproto.NewKVClient(conn)
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+        defer cancel()
+        reply, err := client.CompareAndSwap(ctx, args)
+        if err != nil {
+                log.Println("casValue() is failed", err)
+                return nil, false
+        }
+        return reply, true
+}
+
+func (ck *Clerk) txnValue(address string, args *kvproto.TxnArgs) (*kvproto.TxnReply, bool) {
+        conn, err := grpc.Dial(address, grpc.WithInsecure())
+        if err != nil {
+                log.Printf("txnValue() did not connect: %v", err)
+                return nil, false
+        }
+        defer conn.Close()
+        client := kvproto.NewKVClient(conn)
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+        defer cancel()
+        reply, err := client.Txn(ctx, args)
+        if err != nil {
+                log.Println("txnValue() is failed", err)
+                return nil, false
+        }
+        return reply, true
+}
+
+func (ck *Clerk) scanValue(address string, args *kvproto.ScanArgs) (*kvproto.ScanReply, error) {
+        conn, err := grpc.Dial(address, grpc.WithInsecure())
+        if err != nil {
+                log.Printf("scanValue() did not connect: %v", err)
+                return nil, err
+        }
+        defer conn.Close()
+        client := kvproto.NewKVClient(conn)
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+        defer cancel()
+        reply, err := client.Scan(ctx, args)
+        if err != nil {
+                log.Println("scanValue() is failed", err)
+                return nil, err
+        }
+        return reply, nil
+}
+
+func (ck *Clerk) compactValue(address string, args *kvproto.CompactArgs) (*kvproto.CompactReply, bool) {
+        conn, err := grpc.Dial(address, grpc.WithInsecure())
+        if err != nil {
+                log.Printf("compactValue() did not connect: %v", err)
+                return nil, false
+        }
+        defer conn.Close()
+        client := kvproto.NewKVClient(conn)
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+        defer cancel()
+        reply, err := client.Compact(ctx, args)
+        if err != nil {
+                log.Println("compactValue() is failed", err)
+                return nil, false
+        }
+        return reply, true
+}
+
+func (ck *Clerk) leaseValue(address string, op string, args *kvproto.LeaseArgs, ttl int64) (*kvproto.LeaseReply, bool) {
+        conn, err := grpc.Dial(address, grpc.WithInsecure())
+        if err != nil {
+                log.Printf("leaseValue() did not connect: %v", err)
+                return nil, false
+        }
+        defer conn.Close()
+        client := kvproto.NewKVClient(conn)
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+        defer cancel()
+        var reply *kvproto.LeaseReply
+        switch op {
+        case "LeaseGrant":
+                reply, err = client.LeaseGrant(ctx, &kvproto.LeaseGrantArgs{TTL: ttl, Id: args.Id, Seq: args.Seq, Group: args.Group})
+        case "LeaseKeepAlive":
+                reply, err = client.LeaseKeepAlive(ctx, args)
+        default:
+                reply, err = client.LeaseRevoke(ctx, args)
+        }
+        if err != nil {
+                log.Println("leaseValue() is failed", err)
+                return nil, false
+        }
+        return reply, true
+}
+
 func (ck *Clerk) GetValue(address string, args *kvproto.GetArgs) (*kvproto.GetReply, error) {
         //  grpc.WithInsecure(): client连接server跳过服务器证书的验证，使用明文通讯，会被第三方监听
         conn, err := grpc.Dial(address, grpc.WithInsecure())
@@ -151,12 +836,17 @@ func (ck *Clerk) GetValue(address string, args *kvproto.GetArgs) (*kvproto.GetRe
 }
 
 var count int32 = 0
+var leaseRead = false
+var staleRead = false
 var putCount int32 = 0
 var getCount int32 = 0
 
 func ReadRequest(num int, servers []string) {
         ck := Clerk{
-                servers: make([]string, len(servers)),
+                servers:   make([]string, len(servers)),
+                id:        MakeId(),
+                leaseRead: leaseRead,
+                staleRead: staleRead,
         }
         for i := 0; i < len(servers); i++ {
                 ck.servers[i] = servers[i]
@@ -175,7 +865,10 @@ func ReadRequest(num int, servers []string) {
 func Request(cnum int, num int, servers []string) {
         fmt.Println("servers: ", servers)
         ck := Clerk{
-                servers: make([]string, len(servers)),
+                servers:   make([]string, len(servers)),
+                id:        MakeId(),
+                leaseRead: leaseRead,
+                staleRead: staleRead,
         }
         for i := 0; i < len(servers); i++ {
                 ck.servers[i] = servers[i]
@@ -206,7 +899,10 @@ func Request(cnum int, num int, servers []string) {
 func RequestRatio(cnum int, num int, servers []string, getRatio int) {
         fmt.Println("servers: ", servers)
         ck := Clerk{
-                servers:     make([]string, len(servers)),
+                servers:   make([]string, len(servers)),
+                id:        MakeId(),
+                leaseRead: leaseRead,
+                staleRead: staleRead,
         }
         copy(ck.servers, servers)
         start_time := time.Now()
@@ -247,12 +943,23 @@ func main() {
         var cnums = flag.String("cnums", "1", "Client Threads Number")
         var onums = flag.String("onums", "1", "Client Requests times")
         var getratio = flag.String("getratio", "1", "Get Times per Put Times")
+        var target = flag.String("target", "", "Raft address of the server to add, remove or transfer leadership to")
+        var leaseread = flag.Bool("leaseread", false, "Serve Get with leader lease instead of a heartbeat round")
+        var staleread = flag.Bool("staleread", false, "Serve Get from any server's local data, including learners")
+        var watchkey = flag.String("key", "key", "Key (or prefix with -prefix) to follow in Watch mode")
+        var watchprefix = flag.Bool("prefix", false, "Watch every key starting with -key")
+        // 分片时shard controller的地址，Join、Leave、Move和Query模式修改或者查询shard的分配
+        var ctrlers = flag.String("ctrlers", "", "Shard controller addresses; Join, Leave, Move and Query modes send to them")
+        var gid = flag.Int64("gid", 0, "Replica group to join (with -servers), leave, move -shard to, or send group requests to")
+        var shard = flag.Int("shard", 0, "Shard to move in Move mode")
         // 将命令行参数解析
         flag.Parse()
         servers := strings.Split(*ser, ",")
         clientNumm, _ := strconv.Atoi(*cnums)
         optionNumm, _ := strconv.Atoi(*onums)
         getRatio, _ := strconv.Atoi(*getratio)
+        leaseRead = *leaseread
+        staleRead = *staleread
 
         if clientNumm == 0 {
                 fmt.Println("### Don't forget input -cnum's value ! ###")
@@ -263,6 +970,62 @@ func main() {
                 return
         }
 
+        if *mode == "Join" || *mode == "Leave" || *mode == "Move" || *mode == "Query" {
+                ctrler := shardctrler.MakeClerk(strings.Split(*ctrlers, ","))
+                var num int64
+                var errMsg string
+                switch *mode {
+                case "Join":
+                        num, errMsg = ctrler.Join(*gid, servers)
+                case "Leave":
+                        num, errMsg = ctrler.Leave(*gid)
+                case "Move":
+                        num, errMsg = ctrler.Move(*shard, *gid)
+                }
+                cfg := ctrler.Query(num)
+                fmt.Println(*mode, "config:", cfg.Num, "shards:", cfg.Shards, "groups:", cfg.Groups, errMsg)
+                return
+        }
+
+        // 分片时不带key的请求发给-gid
+        makeClerk := func() *Clerk {
+                if *ctrlers == "" {
+                        return MakeClerk(servers)
+                }
+                ck := MakeShardedClerk(strings.Split(*ctrlers, ","))
+                if !ck.UseGroup(*gid) {
+                        log.Fatalln("group ", *gid, " is not in the latest config")
+                }
+                return ck
+        }
+
+        if *mode == "AddServer" || *mode == "RemoveServer" || *mode == "TransferLeadership" ||
+                *mode == "AddLearner" || *mode == "PromoteLearner" {
+                ck := makeClerk()
+                ok, errMsg := ck.changeMembership(*mode, *target)
+                fmt.Println(*mode, *target, "success:", ok, errMsg)
+                return
+        }
+
+        if *mode == "Watch" {
+                // 输出-key的每一次修改，代替轮询Get
+                ck := makeClerk()
+                ch, errMsg := ck.Watch(context.Background(), *watchkey, *watchprefix, 0)
+                if errMsg != "" {
+                        fmt.Println("### Watch failed:", errMsg, "###")
+                        return
+                }
+                for resp := range ch {
+                        if resp.CompactRevision != 0 {
+                                fmt.Println("revision compacted: ", resp.CompactRevision)
+                        }
+                        for _, e := range resp.Events {
+                                fmt.Println(e.Revision, e.Type, e.Key, e.Value)
+                        }
+                }
+                return
+        }
+
         // 总请求次数Times = clientNumm * optionNumm
         if *mode == "RequestRatio" {
                 for i := 0; i < clientNumm; i++ {
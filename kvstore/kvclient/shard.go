@@ -0,0 +1,103 @@
+package main
+
+import (
+	"time"
+
+	"hckvstore/kvstore/shardctrler"
+	"hckvstore/util"
+)
+
+// 分片：Clerk缓存shard controller的配置，按key所在的shard把请求发给负责它的复制组。
+// 服务端返回ErrWrongGroup说明缓存的配置已经过时，查询最新的配置之后重试同一个请求(Seq不变)；
+// shard在组之间迁移时请求也会收到可以重试的错误，而不是旧的数据。
+// Scan发给配置中的所有组并合并结果，前缀Watch不支持分片，其他不带key的请求(lease、Compact和成员变更)发给UseGroup选择的组
+
+// MakeShardedClerk returns a Clerk that routes every key to the replica
+// group serving its shard, as recorded by the shard controller.
+func MakeShardedClerk(ctrlers []string) *Clerk {
+	ck := MakeClerk(nil)
+	ck.ctrler = shardctrler.MakeClerk(ctrlers)
+	ck.leaders = make(map[int64]int)
+	ck.config = ck.ctrler.Query(0)
+	return ck
+}
+
+// 切换到负责key的复制组，配置中还没有组负责这个shard时等待新的配置
+func (ck *Clerk) route(key string) {
+	if ck.ctrler == nil {
+		return
+	}
+	for !ck.setGroup(ck.config.Shards[shardctrler.Key2Shard(key)]) {
+		time.Sleep(100 * time.Millisecond)
+		ck.config = ck.ctrler.Query(0)
+	}
+}
+
+// UseGroup sends the requests without a key to replica group gid. It
+// returns false if gid is not in the latest configuration.
+func (ck *Clerk) UseGroup(gid int64) bool {
+	if ck.ctrler == nil {
+		return false
+	}
+	if !ck.setGroup(gid) {
+		ck.config = ck.ctrler.Query(0)
+		return ck.setGroup(gid)
+	}
+	return true
+}
+
+func (ck *Clerk) setGroup(gid int64) bool {
+	servers, ok := ck.config.Groups[gid]
+	if !ok {
+		return false
+	}
+	if ck.servers != nil {
+		ck.leaders[ck.group] = ck.leaderId
+	}
+	ck.group, ck.servers, ck.leaderId = gid, servers, ck.leaders[gid]
+	if ck.leaderId >= len(servers) {
+		ck.leaderId = 0
+	}
+	return true
+}
+
+// 服务端返回ErrWrongGroup时查询最新的配置：key所在的组变化时从新组的leader开始重试，
+// 否则这个节点可能还没有apply新配置，换一个节点重试。
+// ErrShardNotReady说明shard正在迁移到这个组，等待一段时间之后重试同一个节点
+func (ck *Clerk) wrongGroup(key string, errMsg string, id *int) bool {
+	if ck.ctrler == nil {
+		return false
+	}
+	if errMsg == shardctrler.ErrShardNotReady {
+		time.Sleep(100 * time.Millisecond)
+		return true
+	}
+	if errMsg != shardctrler.ErrWrongGroup {
+		return false
+	}
+	gid := ck.group
+	time.Sleep(100 * time.Millisecond)
+	ck.config = ck.ctrler.Query(0)
+	ck.route(key)
+	if ck.group != gid {
+		*id = ck.leaderId
+	} else {
+		*id = (*id + 1) % len(ck.servers)
+	}
+	util.DPrintf("wrong group, key: %v, retry group: %v, config: %v", key, ck.group, ck.config.Num)
+	return true
+}
+
+// Txn的所有key必须属于同一个复制组
+func (ck *Clerk) sameGroup(keys []string) bool {
+	if ck.ctrler == nil || len(keys) == 0 {
+		return true
+	}
+	gid := ck.config.Shards[shardctrler.Key2Shard(keys[0])]
+	for _, key := range keys[1:] {
+		if ck.config.Shards[shardctrler.Key2Shard(key)] != gid {
+			return false
+		}
+	}
+	return true
+}
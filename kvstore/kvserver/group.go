@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	config "hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	"hckvstore/kvstore/store"
+	pst "hckvstore/persister"
+	raft "hckvstore/raft"
+	"hckvstore/util"
+)
+
+// leader查询下一个配置的间隔
+const configPollInterval = 100 * time.Millisecond
+
+// group是进程中的一个复制组：独立的Raft、LevelDB和状态机。
+// 同一个进程中的组使用不同的Raft地址，组之间只共用KV服务地址
+type group struct {
+	gid       int64
+	raft      *raft.Raft
+	persister *pst.Persister
+	store     *store.Store // KV状态机，apply Raft提交的Op
+}
+
+// 返回负责key的复制组，本进程中没有组负责key所在的shard时返回nil。
+// 这里只是根据已经apply的配置选择组，写入在apply时还会再检查一次
+func (kv *KVServer) keyGroup(key string) *group {
+	for _, g := range kv.groups {
+		if g.store.CheckKey(key) != shardctrler.ErrWrongGroup {
+			return g
+		}
+	}
+	return nil
+}
+
+// 返回组号为gid的复制组，gid为0时返回第一个组
+func (kv *KVServer) group(gid int64) *group {
+	if gid == 0 {
+		return kv.groups[0]
+	}
+	for _, g := range kv.groups {
+		if g.gid == gid {
+			return g
+		}
+	}
+	return nil
+}
+
+// Txn的所有key需要属于同一个组，按第一个key选择组
+func (kv *KVServer) txnGroup(op config.Op) *group {
+	switch {
+	case len(op.Compares) > 0:
+		return kv.keyGroup(op.Compares[0].Key)
+	case len(op.Success) > 0:
+		return kv.keyGroup(op.Success[0].Key)
+	case len(op.Failure) > 0:
+		return kv.keyGroup(op.Failure[0].Key)
+	}
+	return kv.groups[0]
+}
+
+// leader依次把shard controller的每一个新配置提交到日志，
+// 配置不能跳过，上一个配置的分片迁移完成之后才提交下一个配置
+func (g *group) pollConfig(ctrler *shardctrler.Clerk) {
+	for {
+		time.Sleep(configPollInterval)
+		if _, isLeader := g.raft.GetState(); !isLeader || g.store.Migrating() {
+			continue
+		}
+		num := g.store.Config().Num
+		next := ctrler.Query(num + 1)
+		if next.Num != num+1 {
+			continue
+		}
+		util.DPrintf("group %v new config: %v", g.gid, next.Num)
+		data, _ := json.Marshal(next)
+		g.propose(context.Background(), config.Op{Option: store.OpConfig, Value: string(data)})
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,73 +18,358 @@ import (
 	raft "hckvstore/raft"
 
 	config "hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	"hckvstore/kvstore/store"
 	pst "hckvstore/persister"
+	"hckvstore/util"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
 
+// PutAppend等待日志apply的最长时间，小于client的RPC超时
+const proposalTimeout = 3 * time.Second
+
+// KVServer在一个进程中运行一个或多个复制组，所有组共用一个KV服务地址。
+// 不分片时只有一个组号为0的组，负责所有的key
 type KVServer struct {
-	gossip    *gsp.Gossip
-	raft      *raft.Raft
-	persister *pst.Persister
-	applyCh   chan int
-	delay     int
+	gossip *gsp.Gossip
+	groups []*group
+	ctrler *shardctrler.Clerk // 分片时查询配置，不分片时为nil
+	delay  int
 }
 
 func (kv *KVServer) Get(ctx context.Context, args *kvproto.GetArgs) (*kvproto.GetReply, error) {
 	getReply := &kvproto.GetReply{}
-	_, isLeader := kv.raft.GetState()
+	g := kv.keyGroup(args.Key)
+	if g == nil {
+		getReply.Err = shardctrler.ErrWrongGroup
+		return getReply, nil
+	}
+	isLeader, ok := g.readReady(args.LeaseRead, args.StaleRead)
 	getReply.IsLeader = isLeader
-	if !isLeader {
+	if !ok {
 		// value is ""
 		return getReply, nil
 	}
-	// 生成操作对应的日志
-	op := config.Op{
-		Option: "Get",
-		Key:    args.Key,
+	// 等待apply之后再确认一次，readIndex之前提交的配置可能已经把shard分给了其他组，
+	// 或者shard还在迁移，这时返回可以重试的错误而不是旧的数据
+	if getReply.Err = g.store.CheckKey(args.Key); getReply.Err != "" {
+		return getReply, nil
 	}
-	// 向Raft集群写入日志
-	_, _, isLeader = kv.raft.Start(op)
-	if !isLeader {
-		// value is ""
+	if args.Revision > 0 {
+		var err error
+		getReply.Value, getReply.Version, err = g.store.GetAt(args.Key, args.Revision)
+		if err != nil {
+			getReply.Err = err.Error()
+		}
 		return getReply, nil
 	}
-	getReply.IsLeader = true
-	// Get直接让Leader返回结果
-	getReply.Value = string(kv.persister.Get(args.Key))
+	getReply.Value, getReply.Version = g.store.GetVersion(args.Key)
 	return getReply, nil
 }
 
-func (kv *KVServer) PutAppend(ctx context.Context, args *kvproto.PutAppendArgs) (*kvproto.PutAppendReply, error) {
-	putAppendReply := &kvproto.PutAppendReply{}
-	_, isLeader := kv.raft.GetState()
-	putAppendReply.IsLeader = isLeader
+// 返回本节点是否是leader，以及现在能否读本地数据。
+// stale read时任何节点(包括learner)都直接读本地已经apply的数据，不保证读到最新的写入；
+// 否则用ReadIndex：读不写入日志，确认leader身份并等待状态机apply到readIndex之后再读LevelDB。
+// LeaseRead在lease有效期内省去确认leader身份的心跳
+func (g *group) readReady(leaseRead bool, staleRead bool) (isLeader bool, ok bool) {
+	_, isLeader = g.raft.GetState()
+	if staleRead {
+		return isLeader, true
+	}
 	if !isLeader {
-		return putAppendReply, nil
+		return false, false
+	}
+	if leaseRead {
+		_, isLeader = g.raft.LeaseRead()
+	} else {
+		_, isLeader = g.raft.ReadIndex()
+	}
+	return isLeader, isLeader
+}
+
+func (kv *KVServer) Scan(ctx context.Context, args *kvproto.ScanArgs) (*kvproto.ScanReply, error) {
+	scanReply := &kvproto.ScanReply{}
+	g := kv.group(args.Group)
+	if g == nil {
+		scanReply.Err = shardctrler.ErrWrongGroup
+		return scanReply, nil
+	}
+	isLeader, ok := g.readReady(args.LeaseRead, args.StaleRead)
+	scanReply.IsLeader = isLeader
+	if !ok {
+		return scanReply, nil
+	}
+	kvs, next, err := g.store.Scan(store.ScanOptions{
+		Start:     args.Start,
+		End:       args.End,
+		Prefix:    args.Prefix,
+		Limit:     int(args.Limit),
+		Reverse:   args.Reverse,
+		KeysOnly:  args.KeysOnly,
+		Token:     args.Token,
+		Revision:  args.Revision,
+		ConfigNum: args.ConfigNum,
+	})
+	if err != nil {
+		scanReply.Err = err.Error()
+		return scanReply, nil
 	}
+	for _, item := range kvs {
+		scanReply.Kvs = append(scanReply.Kvs, &kvproto.KeyValue{Key: item.Key, Value: item.Value, Version: item.Version})
+	}
+	scanReply.NextToken = next
+	return scanReply, nil
+}
+
+func (kv *KVServer) PutAppend(ctx context.Context, args *kvproto.PutAppendArgs) (*kvproto.PutAppendReply, error) {
+	putAppendReply := &kvproto.PutAppendReply{}
 	op := config.Op{
 		Option: args.Op,
 		Key:    args.Key,
 		Value:  args.Value,
 		Id:     args.Id,
 		Seq:    args.Seq,
+		Lease:  args.Lease,
 	}
-	index, _, isLeader := kv.raft.Start(op)
-	if !isLeader {
-		// 说明这个过程中 membership发生了变化
-		fmt.Println("Leader Changed !")
-		putAppendReply.IsLeader = false
-		return putAppendReply, nil
+	reply, isLeader, err := kv.propose(ctx, kv.keyGroup(args.Key), op)
+	putAppendReply.IsLeader, putAppendReply.Success, putAppendReply.Err = isLeader, isLeader && err == nil, replyErr(reply, err)
+	return putAppendReply, nil
+}
+
+func (kv *KVServer) Delete(ctx context.Context, args *kvproto.DeleteArgs) (*kvproto.DeleteReply, error) {
+	deleteReply := &kvproto.DeleteReply{}
+	op := config.Op{
+		Option: "Delete",
+		Key:    args.Key,
+		Id:     args.Id,
+		Seq:    args.Seq,
 	}
+	reply, isLeader, err := kv.propose(ctx, kv.keyGroup(args.Key), op)
+	deleteReply.IsLeader, deleteReply.Success, deleteReply.Err = isLeader, isLeader && err == nil, replyErr(reply, err)
+	return deleteReply, nil
+}
+
+var casOptions = map[kvproto.CASCond]string{
+	kvproto.CASCond_VALUE:   store.OpCASValue,
+	kvproto.CASCond_VERSION: store.OpCASVersion,
+	kvproto.CASCond_ABSENT:  store.OpPutIfAbsent,
+	kvproto.CASCond_PRESENT: store.OpPutIfPresent,
+}
 
-	// 如果kv.applyCh空，这里就会阻塞
-	apply := <-kv.applyCh
-	fmt.Println("PutAppend apply success, index: ", index)
-	if apply == 1 {
+func (kv *KVServer) CompareAndSwap(ctx context.Context, args *kvproto.CASArgs) (*kvproto.CASReply, error) {
+	casReply := &kvproto.CASReply{}
+	op := config.Op{
+		Option:          casOptions[args.Cond],
+		Key:             args.Key,
+		Value:           args.Value,
+		Id:              args.Id,
+		Seq:             args.Seq,
+		Expected:        args.Expected,
+		ExpectedVersion: args.ExpectedVersion,
 	}
-	return putAppendReply, nil
+	reply, isLeader, err := kv.propose(ctx, kv.keyGroup(args.Key), op)
+	casReply.IsLeader, casReply.Success, casReply.Err = isLeader, isLeader && err == nil, replyErr(reply, err)
+	casReply.Swapped = casReply.Success && reply.Err == "" && !reply.Mismatch
+	casReply.Value, casReply.Version = reply.Value, reply.Version
+	return casReply, nil
+}
+
+// 压缩作为一条日志提交，所有副本在同一个位置删除旧版本
+func (kv *KVServer) Compact(ctx context.Context, args *kvproto.CompactArgs) (*kvproto.CompactReply, error) {
+	compactReply := &kvproto.CompactReply{}
+	op := config.Op{
+		Option:   store.OpCompact,
+		Revision: args.Revision,
+		Id:       args.Id,
+		Seq:      args.Seq,
+	}
+	reply, isLeader, err := kv.propose(ctx, kv.group(args.Group), op)
+	compactReply.IsLeader, compactReply.Success, compactReply.Err = isLeader, isLeader && err == nil, replyErr(reply, err)
+	compactReply.Revision = reply.Version
+	return compactReply, nil
+}
+
+// 事件来自apply，按提交顺序推送，所以follower和learner也可以提供Watch，不需要经过leader
+func (kv *KVServer) Watch(args *kvproto.WatchArgs, stream kvproto.KV_WatchServer) error {
+	g := kv.group(args.Group)
+	if args.Group == 0 && !args.Prefix {
+		g = kv.keyGroup(args.Key)
+	}
+	if g == nil {
+		return errors.New(shardctrler.ErrWrongGroup)
+	}
+	w, revision, err := g.store.Watch(args.Key, args.Prefix, args.StartRevision)
+	if c, ok := err.(*store.CompactedError); ok {
+		return stream.Send(&kvproto.WatchResponse{Revision: revision, CompactRevision: c.Revision})
+	}
+	defer w.Close()
+	if err := stream.Send(&kvproto.WatchResponse{Created: true, Revision: revision}); err != nil {
+		return err
+	}
+	for {
+		events, err := w.Next(stream.Context())
+		if err == store.ErrWatchCanceled {
+			return stream.Send(&kvproto.WatchResponse{Revision: revision, Canceled: true})
+		} else if err != nil {
+			return err
+		}
+		resp := &kvproto.WatchResponse{}
+		for _, e := range events {
+			event := &kvproto.Event{Type: kvproto.EventType_EVENT_PUT, Key: e.Key, Value: e.Value, Revision: e.Revision}
+			if e.Type == store.EventDelete {
+				event.Type = kvproto.EventType_EVENT_DELETE
+			}
+			resp.Events = append(resp.Events, event)
+			revision = e.Revision
+		}
+		resp.Revision = revision
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+var (
+	compareTargets = map[kvproto.CompareTarget]string{
+		kvproto.CompareTarget_CMP_VALUE:   "Value",
+		kvproto.CompareTarget_CMP_VERSION: "Version",
+	}
+	compareResults = map[kvproto.CompareResult]string{
+		kvproto.CompareResult_EQUAL:     "=",
+		kvproto.CompareResult_NOT_EQUAL: "!=",
+		kvproto.CompareResult_GREATER:   ">",
+		kvproto.CompareResult_LESS:      "<",
+	}
+	txnOpTypes = map[kvproto.TxnOpType]string{
+		kvproto.TxnOpType_PUT:    "Put",
+		kvproto.TxnOpType_APPEND: "Append",
+		kvproto.TxnOpType_DELETE: "Delete",
+		kvproto.TxnOpType_GET:    "Get",
+	}
+)
+
+// 整个Txn作为一条日志提交，由状态机在一次apply中判断条件并执行操作
+func (kv *KVServer) Txn(ctx context.Context, args *kvproto.TxnArgs) (*kvproto.TxnReply, error) {
+	txnReply := &kvproto.TxnReply{}
+	op := config.Op{
+		Option:  store.OpTxn,
+		Id:      args.Id,
+		Seq:     args.Seq,
+		Success: txnOps(args.Success),
+		Failure: txnOps(args.Failure),
+	}
+	for _, c := range args.Compares {
+		op.Compares = append(op.Compares, config.Compare{
+			Key:     c.Key,
+			Target:  compareTargets[c.Target],
+			Result:  compareResults[c.Result],
+			Value:   c.Value,
+			Version: c.Version,
+		})
+	}
+	reply, isLeader, err := kv.propose(ctx, kv.txnGroup(op), op)
+	txnReply.IsLeader, txnReply.Success, txnReply.Err = isLeader, isLeader && err == nil, replyErr(reply, err)
+	txnReply.Succeeded = txnReply.Success && reply.Err == "" && !reply.Mismatch
+	for _, r := range reply.Results {
+		txnReply.Results = append(txnReply.Results, &kvproto.TxnResult{Value: r.Value, Version: r.Version})
+	}
+	return txnReply, nil
+}
+
+func txnOps(ops []*kvproto.TxnOp) []config.Op {
+	var res []config.Op
+	for _, o := range ops {
+		res = append(res, config.Op{Option: txnOpTypes[o.Type], Key: o.Key, Value: o.Value, Lease: o.Lease})
+	}
+	return res
+}
+
+// 把op交给复制组g，g为nil说明本进程中没有组负责op的key
+func (kv *KVServer) propose(ctx context.Context, g *group, op config.Op) (reply store.Reply, isLeader bool, err error) {
+	if g == nil {
+		return store.Reply{Err: shardctrler.ErrWrongGroup}, false, nil
+	}
+	return g.propose(ctx, op)
+}
+
+// 把op写入日志并等待apply，返回状态机的执行结果。isLeader为false时client应该换一个节点重试；
+// isLeader为true而err不为nil时结果未知，client用相同的Seq重试，由去重表保证只执行一次
+func (g *group) propose(ctx context.Context, op config.Op) (reply store.Reply, isLeader bool, err error) {
+	op.Time = time.Now().UnixNano() / int64(time.Millisecond)
+	proposal, err := g.raft.Propose(op)
+	if err != nil {
+		return reply, false, err
+	}
+
+	// 在client的超时之前回复，让client知道结果未知，而不是连接错误
+	ctx, cancel := context.WithTimeout(ctx, proposalTimeout)
+	defer cancel()
+	switch err := proposal.Wait(ctx); err {
+	case nil:
+		util.DPrintf("%v apply success, index: %v", op.Option, proposal.Index)
+		// 重复的请求返回第一次执行的结果
+		reply, _ = proposal.Result().(store.Reply)
+		return reply, true, nil
+	case raft.ErrProposalDropped:
+		// 日志被新leader覆盖，写入一定没有生效
+		util.DPrintf("Leader Changed !")
+		return reply, false, err
+	default:
+		return reply, true, err
+	}
+}
+
+// 返回给client的错误信息：结果未知的原因，或者状态机执行命令的错误
+func replyErr(reply store.Reply, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return reply.Err
+}
+
+// 管理接口：把一个Raft节点加入集群，需要先用不在-members中的地址启动这个节点
+func (kv *KVServer) AddServer(ctx context.Context, args *kvproto.MembershipArgs) (*kvproto.MembershipReply, error) {
+	return kv.changeMembership(args, (*raft.Raft).AddServer), nil
+}
+
+// 管理接口：把一个Raft节点移出集群，被移除的节点不再参与选举和日志复制
+func (kv *KVServer) RemoveServer(ctx context.Context, args *kvproto.MembershipArgs) (*kvproto.MembershipReply, error) {
+	return kv.changeMembership(args, (*raft.Raft).RemoveServer), nil
+}
+
+// 管理接口：增加一个learner，只复制日志，不参与选举和提交的多数派
+func (kv *KVServer) AddLearner(ctx context.Context, args *kvproto.MembershipArgs) (*kvproto.MembershipReply, error) {
+	return kv.changeMembership(args, (*raft.Raft).AddLearner), nil
+}
+
+// 管理接口：learner追上leader的日志之后提升为voter
+func (kv *KVServer) PromoteLearner(ctx context.Context, args *kvproto.MembershipArgs) (*kvproto.MembershipReply, error) {
+	return kv.changeMembership(args, (*raft.Raft).PromoteLearner), nil
+}
+
+// 管理接口：把领导权转移给另一个成员，例如在下线当前leader所在的机器之前
+func (kv *KVServer) TransferLeadership(ctx context.Context, args *kvproto.MembershipArgs) (*kvproto.MembershipReply, error) {
+	return kv.changeMembership(args, (*raft.Raft).TransferLeadership), nil
+}
+
+// 在args.Group的Raft上执行成员变更
+func (kv *KVServer) changeMembership(args *kvproto.MembershipArgs, change func(rf *raft.Raft, address string) error) *kvproto.MembershipReply {
+	g := kv.group(args.Group)
+	if g == nil {
+		// 每个节点都会给出同样的回复，IsLeader让客户端停止重试其他节点
+		return &kvproto.MembershipReply{IsLeader: true, Err: shardctrler.ErrWrongGroup}
+	}
+	return membershipReply(change(g.raft, args.Address))
+}
+
+func membershipReply(err error) *kvproto.MembershipReply {
+	reply := &kvproto.MembershipReply{IsLeader: err != raft.ErrNotLeader, Success: err == nil}
+	if err != nil {
+		reply.Err = err.Error()
+	}
+	return reply
 }
 
 func (kv *KVServer) RegisterServer(address string) {
@@ -109,26 +396,61 @@ func (kv *KVServer) RegisterServer(address string) {
 }
 
 func main() {
+	// 一个进程可以运行多个复制组，-gids、-address和-members用;分隔每个组的参数
+	var gidList = flag.String("gids", "0", "Replica group ids hosted by this process, separated by ';', 0 for an unsharded store")
 	var add = flag.String("address", "", "Input Your address")
 	var mems = flag.String("members", "", "Input Your follower")
+	// 所有组共用的KV服务地址，默认为第一个组的Raft地址后面加1
+	var kvaddr = flag.String("kvaddress", "", "Address of the KV service shared by all groups")
+	// shard controller的服务地址，分片时必须设置
+	var ctrlers = flag.String("ctrlers", "", "Shard controller addresses, separated by ','")
+	// Raft持久化状态超过这个大小(字节)时做快照，-1表示不做快照
+	var maxraftstate = flag.Int("maxraftstate", 1<<20, "Snapshot threshold of raft state in bytes, -1 to disable")
+	// lease read的时钟漂移余量，lease = 最小选举超时 - clockdrift
+	var clockdrift = flag.Int("clockdrift", 100, "Clock drift margin of leader lease in ms")
+	// 客户端会话的过期时间，集群中所有节点必须相同
+	var sessionttl = flag.Int("sessionttl", int(store.DefaultSessionTTL/time.Second), "Expire idle client sessions after this many seconds, 0 to keep them forever")
 	// var delays = flag.String("delay", "", "Input Your follower")
 	flag.Parse()
-	address := *add
-	members := strings.Split(*mems, ",")
+	gids := strings.Split(*gidList, ";")
+	addresses := strings.Split(*add, ";")
+	memberLists := strings.Split(*mems, ";")
+	if len(addresses) != len(gids) || len(memberLists) != len(gids) {
+		log.Fatalln("-gids, -address and -members must list the same number of groups")
+	}
 	// delay, _ := strconv.Atoi(*delays)
 
 	kvserver := &KVServer{}
-	persister := &pst.Persister{}
-	kvserver.persister = persister
-	// kvserver.persister.Init("/home/jason/hybrid_consistency/db/" + address)
-	kvserver.persister.Init("../db/" + address)
-	// 缓冲通道可以并行处理100个log apply
-	kvserver.applyCh = make(chan int, 100)
-	go kvserver.RegisterServer(address + "1")
+	if *ctrlers != "" {
+		kvserver.ctrler = shardctrler.MakeClerk(strings.Split(*ctrlers, ","))
+	}
 	// delay 默认为0，同一数据中心内
 	kvserver.delay = 0
-	kvserver.gossip = gsp.MakeGossip(address)
-	kvserver.raft = raft.MakeRaft(address, members, persister, &sync.Mutex{}, kvserver.applyCh, kvserver.delay)
+	kvserver.gossip = gsp.MakeGossip(addresses[0])
+	for i := range gids {
+		gid, err := strconv.ParseInt(gids[i], 10, 64)
+		if err != nil {
+			log.Fatalln("invalid group id: ", gids[i])
+		}
+		if gid != 0 && kvserver.ctrler == nil {
+			log.Fatalln("group ", gid, " is sharded, -ctrlers must be set")
+		}
+		g := &group{gid: gid, persister: &pst.Persister{}}
+		// g.persister.Init("/home/jason/hybrid_consistency/db/" + addresses[i])
+		g.persister.Init("../db/" + addresses[i])
+		g.store = store.MakeGroupStore(g.persister, time.Duration(*sessionttl)*time.Second, gid)
+		g.raft = raft.MakeRaft(addresses[i], strings.Split(memberLists[i], ","), g.persister, &sync.Mutex{}, g.store, kvserver.delay, *maxraftstate, time.Duration(*clockdrift)*time.Millisecond)
+		kvserver.groups = append(kvserver.groups, g)
+		go g.expireLeases()
+		if gid != 0 {
+			go g.pollConfig(kvserver.ctrler)
+			go g.migrateShards()
+		}
+	}
+	if *kvaddr == "" {
+		*kvaddr = addresses[0] + "1"
+	}
+	go kvserver.RegisterServer(*kvaddr)
 
 	// server运行20min
 	time.Sleep(time.Second * 1200)
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	config "hckvstore/config"
+	"hckvstore/kvstore/store"
+	kvproto "hckvstore/rpc/kvrpc"
+	"hckvstore/util"
+)
+
+// leader检查lease是否到期的间隔
+const leaseCheckInterval = 500 * time.Millisecond
+
+func (kv *KVServer) LeaseGrant(ctx context.Context, args *kvproto.LeaseGrantArgs) (*kvproto.LeaseReply, error) {
+	op := config.Op{Option: store.OpLeaseGrant, TTL: args.TTL * 1000, Id: args.Id, Seq: args.Seq}
+	return kv.leaseOp(ctx, kv.group(args.Group), op), nil
+}
+
+func (kv *KVServer) LeaseKeepAlive(ctx context.Context, args *kvproto.LeaseArgs) (*kvproto.LeaseReply, error) {
+	op := config.Op{Option: store.OpLeaseKeepAlive, Lease: args.Lease, Id: args.Id, Seq: args.Seq}
+	return kv.leaseOp(ctx, kv.group(args.Group), op), nil
+}
+
+func (kv *KVServer) LeaseRevoke(ctx context.Context, args *kvproto.LeaseArgs) (*kvproto.LeaseReply, error) {
+	op := config.Op{Option: store.OpLeaseRevoke, Lease: args.Lease, Id: args.Id, Seq: args.Seq}
+	return kv.leaseOp(ctx, kv.group(args.Group), op), nil
+}
+
+func (kv *KVServer) leaseOp(ctx context.Context, g *group, op config.Op) *kvproto.LeaseReply {
+	leaseReply := &kvproto.LeaseReply{}
+	reply, isLeader, err := kv.propose(ctx, g, op)
+	leaseReply.IsLeader, leaseReply.Success, leaseReply.Err = isLeader, isLeader && err == nil, replyErr(reply, err)
+	leaseReply.Lease, leaseReply.TTL = reply.Lease, reply.TTL/1000
+	return leaseReply
+}
+
+// leader定期检查到期的lease并提议LeaseExpire，由apply删除lease和关联的key。
+// 到期时间是之前的leader的时钟，新leader当选之后先给每个lease一个完整的TTL，
+// 让client有时间向新leader续约，避免时钟差异或者选举期间无法续约导致lease提前到期
+func (g *group) expireLeases() {
+	var term int32
+	var leaderSince int64
+	proposed := make(map[int64]int64) // 已经提议过LeaseExpire的lease和提议的时间
+	for {
+		time.Sleep(leaseCheckInterval)
+		t, isLeader := g.raft.GetState()
+		now := time.Now().UnixNano() / int64(time.Millisecond)
+		if !isLeader {
+			continue
+		}
+		if t != term {
+			term, leaderSince = t, now
+		}
+		for _, l := range g.store.Leases() {
+			if now < l.Expiry || now < leaderSince+l.TTL || now-proposed[l.ID] < int64(proposalTimeout/time.Millisecond) {
+				continue
+			}
+			util.DPrintf("lease expired: %v", l.ID)
+			// 不等待结果，lease没有被删除时超过proposalTimeout之后再次提议
+			if _, err := g.raft.Propose(config.Op{Option: store.OpLeaseExpire, Lease: l.ID, Time: now}); err != nil {
+				break
+			}
+			proposed[l.ID] = now
+		}
+		for id, at := range proposed {
+			if now-at >= int64(proposalTimeout/time.Millisecond) {
+				delete(proposed, id)
+			}
+		}
+	}
+}
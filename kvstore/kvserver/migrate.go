@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	config "hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	"hckvstore/kvstore/store"
+	kvproto "hckvstore/rpc/kvrpc"
+	"hckvstore/util"
+
+	"google.golang.org/grpc"
+)
+
+// 分片迁移的RPC：args.Group是原来负责shard的组，由它的leader回复(迁移的过程见store/migrate.go)
+
+// 返回冻结的shard的数据。ReadIndex保证读到的是所有已经提交的日志apply之后的状态
+func (kv *KVServer) PullShard(ctx context.Context, args *kvproto.ShardArgs) (*kvproto.ShardReply, error) {
+	shardReply := &kvproto.ShardReply{}
+	g := kv.group(args.Group)
+	if g == nil {
+		shardReply.Err = shardctrler.ErrWrongGroup
+		return shardReply, nil
+	}
+	isLeader, ok := g.readReady(false, false)
+	shardReply.IsLeader = isLeader
+	if !ok {
+		return shardReply, nil
+	}
+	shardReply.Data, shardReply.Err = g.store.ExportShard(args.ConfigNum, int(args.Shard))
+	shardReply.Success = shardReply.Err == ""
+	return shardReply, nil
+}
+
+// 新的组已经安装了shard，通过日志删除本组中冻结的数据。重复的请求在apply时被忽略
+func (kv *KVServer) DeleteShard(ctx context.Context, args *kvproto.ShardArgs) (*kvproto.ShardReply, error) {
+	shardReply := &kvproto.ShardReply{}
+	op := config.Op{Option: store.OpDeleteShard, Shard: args.Shard, ConfigNum: args.ConfigNum}
+	reply, isLeader, err := kv.propose(ctx, kv.group(args.Group), op)
+	shardReply.IsLeader, shardReply.Success, shardReply.Err = isLeader, isLeader && err == nil, replyErr(reply, err)
+	return shardReply, nil
+}
+
+// leader推进当前配置的分片迁移：拉取新分给本组的shard并提交到日志，
+// 安装之后通知原来的组删除数据，确认之后提交OpShardDeleted
+func (g *group) migrateShards() {
+	for {
+		time.Sleep(configPollInterval)
+		if _, isLeader := g.raft.GetState(); !isLeader {
+			continue
+		}
+		for _, p := range g.store.PendingShards() {
+			args := &kvproto.ShardArgs{Group: p.Group, ConfigNum: p.Num, Shard: int32(p.Shard)}
+			op := config.Op{Shard: args.Shard, ConfigNum: p.Num}
+			if p.Pull {
+				reply, ok := callGroup(p.Servers, func(client kvproto.KVClient, ctx context.Context) (*kvproto.ShardReply, error) {
+					return client.PullShard(ctx, args)
+				})
+				if !ok {
+					continue
+				}
+				op.Option, op.Value = store.OpInstallShard, string(reply.Data)
+			} else {
+				if _, ok := callGroup(p.Servers, func(client kvproto.KVClient, ctx context.Context) (*kvproto.ShardReply, error) {
+					return client.DeleteShard(ctx, args)
+				}); !ok {
+					continue
+				}
+				op.Option = store.OpShardDeleted
+			}
+			util.DPrintf("group %v %v shard: %v, config: %v", g.gid, op.Option, p.Shard, p.Num)
+			g.propose(context.Background(), op)
+		}
+	}
+}
+
+// 依次向组的每个节点发送请求，返回第一个成功的回复
+func callGroup(servers []string, send func(client kvproto.KVClient, ctx context.Context) (*kvproto.ShardReply, error)) (*kvproto.ShardReply, bool) {
+	for _, address := range servers {
+		conn, err := grpc.Dial(address, grpc.WithInsecure())
+		if err != nil {
+			log.Printf("callGroup() did not connect: %v", err)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		reply, err := send(kvproto.NewKVClient(conn), ctx)
+		cancel()
+		conn.Close()
+		if err == nil && reply.Success {
+			return reply, true
+		}
+	}
+	return nil, false
+}
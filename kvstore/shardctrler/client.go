@@ -0,0 +1,113 @@
+package shardctrler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	ctrlerproto "hckvstore/rpc/ctrlerrpc"
+
+	"google.golang.org/grpc"
+)
+
+// Clerk是shard controller的client，KV服务的leader和client都通过它查询配置
+type Clerk struct {
+	servers  []string
+	leaderId int
+}
+
+func MakeClerk(servers []string) *Clerk {
+	return &Clerk{servers: servers}
+}
+
+// Query returns the configuration numbered num, or the latest one if num is
+// 0 or larger than the latest number. It retries until a leader answers.
+func (ck *Clerk) Query(num int64) Config {
+	var cfg Config
+	ck.call(func(client ctrlerproto.ShardCtrlerClient, ctx context.Context) bool {
+		reply, err := client.Query(ctx, &ctrlerproto.QueryArgs{Num: num})
+		if err != nil || !reply.IsLeader {
+			return false
+		}
+		cfg = FromProto(reply.Config)
+		return true
+	})
+	return cfg
+}
+
+// Join、Leave和Move返回执行之后最新的配置编号，以及状态机拒绝命令的原因
+func (ck *Clerk) Join(gid int64, servers []string) (int64, string) {
+	return ck.change(func(client ctrlerproto.ShardCtrlerClient, ctx context.Context) (*ctrlerproto.CtrlerReply, error) {
+		return client.Join(ctx, &ctrlerproto.JoinArgs{Group: gid, Servers: servers})
+	})
+}
+
+func (ck *Clerk) Leave(gid int64) (int64, string) {
+	return ck.change(func(client ctrlerproto.ShardCtrlerClient, ctx context.Context) (*ctrlerproto.CtrlerReply, error) {
+		return client.Leave(ctx, &ctrlerproto.LeaveArgs{Group: gid})
+	})
+}
+
+func (ck *Clerk) Move(shard int, gid int64) (int64, string) {
+	return ck.change(func(client ctrlerproto.ShardCtrlerClient, ctx context.Context) (*ctrlerproto.CtrlerReply, error) {
+		return client.Move(ctx, &ctrlerproto.MoveArgs{Shard: int32(shard), Group: gid})
+	})
+}
+
+// 命令是幂等的，结果未知时直接重试
+func (ck *Clerk) change(send func(ctrlerproto.ShardCtrlerClient, context.Context) (*ctrlerproto.CtrlerReply, error)) (int64, string) {
+	var reply *ctrlerproto.CtrlerReply
+	ck.call(func(client ctrlerproto.ShardCtrlerClient, ctx context.Context) bool {
+		r, err := send(client, ctx)
+		if err != nil || !r.Success {
+			return false
+		}
+		reply = r
+		return true
+	})
+	return reply.Num, reply.Err
+}
+
+// 依次向每个节点发送请求直到fn返回true，每一轮都失败之后等待一段时间再重试
+func (ck *Clerk) call(fn func(client ctrlerproto.ShardCtrlerClient, ctx context.Context) bool) {
+	id := ck.leaderId
+	for i := 1; ; i++ {
+		if ck.try(ck.servers[id], fn) {
+			ck.leaderId = id
+			return
+		}
+		id = (id + 1) % len(ck.servers)
+		if i%len(ck.servers) == 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+func (ck *Clerk) try(address string, fn func(client ctrlerproto.ShardCtrlerClient, ctx context.Context) bool) bool {
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		log.Printf("shard controller did not connect: %v", err)
+		return false
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	return fn(ctrlerproto.NewShardCtrlerClient(conn), ctx)
+}
+
+func (c Config) ToProto() *ctrlerproto.Config {
+	m := &ctrlerproto.Config{Num: c.Num, Shards: c.Shards[:]}
+	for _, gid := range c.gids() {
+		m.Groups = append(m.Groups, &ctrlerproto.Group{Group: gid, Servers: c.Groups[gid]})
+	}
+	return m
+}
+
+func FromProto(m *ctrlerproto.Config) Config {
+	c := Config{Num: m.GetNum(), Groups: make(map[int64][]string)}
+	copy(c.Shards[:], m.GetShards())
+	for _, g := range m.GetGroups() {
+		c.Groups[g.Group] = g.Servers
+	}
+	return c
+}
@@ -0,0 +1,91 @@
+package shardctrler
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// key按hash分到NShards个shard，每个shard由一个复制组(group)负责。
+// Config是shard到复制组的一个分配，每次Join、Leave或Move都生成编号加一的新配置，
+// 编号为0的初始配置没有任何复制组，所有shard都没有分配。
+
+const NShards = 10
+
+const (
+	// 请求的key所在的shard不由这个复制组负责，client需要查询最新的配置之后重试
+	ErrWrongGroup = "wrong group"
+	// 复制组还没有apply请求中的配置，或者shard已经分给了这个复制组但是还在从原来的组迁移数据，client稍后重试
+	ErrShardNotReady = "shard not ready"
+)
+
+type Config struct {
+	Num    int64
+	Shards [NShards]int64     // shard i所属的复制组，0表示没有分配
+	Groups map[int64][]string // 复制组的KV服务地址
+}
+
+// Key2Shard returns the shard that key belongs to.
+func Key2Shard(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % NShards)
+}
+
+func (c Config) copy() Config {
+	res := Config{Num: c.Num, Shards: c.Shards, Groups: make(map[int64][]string)}
+	for gid, servers := range c.Groups {
+		res.Groups[gid] = append([]string{}, servers...)
+	}
+	return res
+}
+
+// 返回排好序的复制组，保证所有副本得到相同的分配
+func (c Config) gids() []int64 {
+	var gids []int64
+	for gid := range c.Groups {
+		gids = append(gids, gid)
+	}
+	sort.Slice(gids, func(i, j int) bool { return gids[i] < gids[j] })
+	return gids
+}
+
+// 在复制组之间均衡shard：每个组负责的shard数量最多相差1，并且尽量少地移动shard。
+// 没有分配或者所属的组已经离开的shard先分给负责shard最少的组
+func (c *Config) rebalance() {
+	gids := c.gids()
+	if len(gids) == 0 {
+		c.Shards = [NShards]int64{}
+		return
+	}
+	owned := make(map[int64][]int)
+	var free []int
+	for shard, gid := range c.Shards {
+		if _, ok := c.Groups[gid]; ok {
+			owned[gid] = append(owned[gid], shard)
+		} else {
+			free = append(free, shard)
+		}
+	}
+	// 负责shard多的组排在前面，多出来的一个shard优先留给它们，减少移动
+	sort.SliceStable(gids, func(i, j int) bool { return len(owned[gids[i]]) > len(owned[gids[j]]) })
+	target := func(i int) int {
+		if i < NShards%len(gids) {
+			return NShards/len(gids) + 1
+		}
+		return NShards / len(gids)
+	}
+	for i, gid := range gids {
+		if n := target(i); len(owned[gid]) > n {
+			free = append(free, owned[gid][n:]...)
+			owned[gid] = owned[gid][:n]
+		}
+	}
+	sort.Ints(free)
+	for i, gid := range gids {
+		for len(owned[gid]) < target(i) {
+			c.Shards[free[0]] = gid
+			owned[gid] = append(owned[gid], free[0])
+			free = free[1:]
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package shardctrler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"hckvstore/config"
+	pst "hckvstore/persister"
+	"hckvstore/raft"
+	"hckvstore/util"
+)
+
+// Ctrler是shard controller的状态机，实现raft.StateMachine：
+// 日志中的命令是Option为Join、Leave或Move的config.Op，每一个改变了分配的命令生成一个新配置。
+// 所有配置保存在和Raft共用的LevelDB中，快照就是全部配置。
+//
+// 客户端重试的Join、Leave和Move不改变分配，不生成新配置，所以不需要客户端会话去重。
+// 一组命令重放时不是幂等的(例如Join(1)、Join(2)、Leave(2))，重启之后重新apply的日志按持久化的下标跳过。
+
+const (
+	OpJoin  = "Join"
+	OpLeave = "Leave"
+	OpMove  = "Move"
+)
+
+const (
+	ErrUnknownOp      = "unknown op"
+	ErrInvalidGroup   = "invalid group"
+	ErrInvalidShard   = "invalid shard"
+	ErrGroupNotJoined = "group not joined"
+)
+
+const (
+	configPrefix = pst.ReservedPrefix + "ctrler/config/"
+	latestKey    = pst.ReservedPrefix + "ctrler/latest"
+)
+
+type Ctrler struct {
+	persister *pst.Persister
+}
+
+func MakeCtrler(persister *pst.Persister) *Ctrler {
+	return &Ctrler{persister: persister}
+}
+
+// Reply是一条命令的执行结果，Num是执行之后最新的配置编号
+type Reply struct {
+	Err string
+	Num int64
+}
+
+func configKey(num int64) string {
+	return fmt.Sprintf("%s%020d", configPrefix, num)
+}
+
+func (c *Ctrler) Apply(msg raft.ApplyMsg) interface{} {
+	index := int64(msg.CommandIndex)
+	if index <= c.persister.Applied() {
+		// 重启之后Raft从快照的位置重新apply日志，已经apply过的日志不再执行
+		return nil
+	}
+	// 新配置和apply到的下标写入同一个batch
+	var b pst.Batch
+	b.SetApplied(index)
+	var reply interface{}
+	if msg.CommandValid {
+		reply = c.execute(msg, &b)
+	}
+	c.persister.Write(&b)
+	return reply
+}
+
+// 执行一条Join、Leave或Move命令，生成的新配置写入b
+func (c *Ctrler) execute(msg raft.ApplyMsg, b *pst.Batch) interface{} {
+	op, err := config.DecodeOp(msg.Command)
+	if err != nil {
+		util.DPrintf("decode op failed: %v, index: %v", err, msg.CommandIndex)
+		return nil
+	}
+	latest := c.Query(0)
+	next := latest.copy()
+	switch op.Option {
+	case OpJoin:
+		if op.Group <= 0 || len(op.Servers) == 0 {
+			return Reply{Err: ErrInvalidGroup, Num: latest.Num}
+		}
+		next.Groups[op.Group] = op.Servers
+		next.rebalance()
+	case OpLeave:
+		delete(next.Groups, op.Group)
+		next.rebalance()
+	case OpMove:
+		if op.Shard < 0 || op.Shard >= NShards {
+			return Reply{Err: ErrInvalidShard, Num: latest.Num}
+		}
+		if _, ok := next.Groups[op.Group]; !ok {
+			return Reply{Err: ErrGroupNotJoined, Num: latest.Num}
+		}
+		next.Shards[op.Shard] = op.Group
+	default:
+		return Reply{Err: ErrUnknownOp, Num: latest.Num}
+	}
+	if sameConfig(latest, next) {
+		return Reply{Num: latest.Num}
+	}
+	next.Num = latest.Num + 1
+	util.DPrintf("%v group: %v, config: %v, shards: %v", op.Option, op.Group, next.Num, next.Shards)
+	data, _ := json.Marshal(next)
+	b.Put(configKey(next.Num), data)
+	b.Put(latestKey, []byte(strconv.FormatInt(next.Num, 10)))
+	return Reply{Num: next.Num}
+}
+
+func sameConfig(a, b Config) bool {
+	if a.Shards != b.Shards || len(a.Groups) != len(b.Groups) {
+		return false
+	}
+	for gid, servers := range a.Groups {
+		other, ok := b.Groups[gid]
+		if !ok || len(other) != len(servers) {
+			return false
+		}
+		for i := range servers {
+			if servers[i] != other[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Query returns the configuration numbered num, or the latest one if num is
+// 0 or larger than the latest number.
+func (c *Ctrler) Query(num int64) Config {
+	cfg := Config{Groups: make(map[int64][]string)}
+	c.persister.View(func(r pst.Reader) {
+		var latest int64
+		if data, ok := r.Lookup(latestKey); ok {
+			json.Unmarshal(data, &latest)
+		}
+		if num <= 0 || num > latest {
+			num = latest
+		}
+		// 还没有任何Join时没有保存的配置，返回初始配置
+		if data, ok := r.Lookup(configKey(num)); ok {
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				log.Fatalln("decode config failed: ", err)
+			}
+		}
+	})
+	if cfg.Groups == nil {
+		cfg.Groups = make(map[int64][]string)
+	}
+	return cfg
+}
+
+func (c *Ctrler) Snapshot() []byte {
+	return c.persister.Snapshot()
+}
+
+func (c *Ctrler) Restore(index int32, snapshot []byte) {
+	c.persister.RestoreSnapshot(snapshot, int64(index))
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"strconv"
+
+	"hckvstore/config"
+	pst "hckvstore/persister"
+)
+
+// 每个key有一个版本，等于最近一次修改它的日志下标，所有副本相同，删除之后回到0。
+// 版本保存在保留key下，和值在同一个batch中写入。
+// 条件写入在apply时判断条件，条件成立才写入新值，判断和写入之间不会有其他命令。
+
+// 条件写入的Op.Option
+const (
+	OpCASValue     = "CASValue"     // 当前值等于Op.Expected，key不存在时不成立
+	OpCASVersion   = "CASVersion"   // 当前版本等于Op.ExpectedVersion，0表示key不存在
+	OpPutIfAbsent  = "PutIfAbsent"  // key不存在
+	OpPutIfPresent = "PutIfPresent" // key存在
+)
+
+const versionPrefix = pst.ReservedPrefix + "kv/version/"
+
+func versionKey(key string) string {
+	return versionPrefix + key
+}
+
+func readVersion(lookup func(key string) ([]byte, bool), key string) int64 {
+	data, ok := lookup(versionKey(key))
+	if !ok {
+		return 0
+	}
+	version, _ := strconv.ParseInt(string(data), 10, 64)
+	return version
+}
+
+func (s *Store) compareAndSwap(op config.Op, index int64, w *writes) Reply {
+	if !w.leaseExists(op.Lease) {
+		return Reply{Err: ErrLeaseNotFound}
+	}
+	current, present := w.lookup(op.Key)
+	version := w.version(op.Key)
+	var matched bool
+	switch op.Option {
+	case OpCASValue:
+		matched = present && string(current) == op.Expected
+	case OpCASVersion:
+		matched = present && version == op.ExpectedVersion || !present && op.ExpectedVersion == 0
+	case OpPutIfAbsent:
+		matched = !present
+	case OpPutIfPresent:
+		matched = present
+	}
+	if !matched {
+		return Reply{Mismatch: true, Value: string(current), Version: version}
+	}
+	w.put(op.Key, []byte(op.Value), index)
+	w.attach(op.Key, op.Lease)
+	return Reply{Value: op.Value, Version: index}
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"hckvstore/config"
+	pst "hckvstore/persister"
+	"hckvstore/util"
+)
+
+// Lease：key可以关联到一个lease，lease被撤销时删除所有关联的key，用于临时的注册信息。
+// lease的ID是授予它的日志下标。到期时间按日志中leader写入的Op.Time计算，
+// 但副本不会自己删除到期的lease：leader发现lease到期之后提议一条LeaseExpire日志，
+// apply时按这条日志的Op.Time再次判断是否到期，所有副本的结果相同。
+// lease保存在LevelDB的保留key下，包含在快照中，leader切换之后仍然有效。
+
+const (
+	OpLeaseGrant     = "LeaseGrant"
+	OpLeaseKeepAlive = "LeaseKeepAlive"
+	OpLeaseRevoke    = "LeaseRevoke"
+	OpLeaseExpire    = "LeaseExpire"
+)
+
+// lease不存在，或者已经到期被撤销
+const ErrLeaseNotFound = "lease not found"
+
+const (
+	leasePrefix    = pst.ReservedPrefix + "kv/lease/"
+	keyLeasePrefix = pst.ReservedPrefix + "kv/keylease/"
+)
+
+type lease struct {
+	ID     int64
+	TTL    int64           // 毫秒
+	Expiry int64           // 到期的Op.Time
+	Keys   map[string]bool `json:",omitempty"`
+}
+
+// LeaseInfo describes a granted lease. TTL and Expiry are in milliseconds,
+// Expiry on the clock of the leaders that proposed the lease operations.
+type LeaseInfo struct {
+	ID     int64
+	TTL    int64
+	Expiry int64
+}
+
+func leaseKey(id int64) string {
+	return fmt.Sprintf("%s%020d", leasePrefix, id)
+}
+
+func keyLeaseKey(key string) string {
+	return keyLeasePrefix + key
+}
+
+func (w *writes) lease(id int64) (*lease, bool) {
+	data, ok := w.lookup(leaseKey(id))
+	if !ok {
+		return nil, false
+	}
+	l := &lease{}
+	if err := json.Unmarshal(data, l); err != nil {
+		log.Fatalln("decode lease failed: ", err)
+	}
+	return l, true
+}
+
+func (w *writes) saveLease(l *lease) {
+	data, _ := json.Marshal(l)
+	w.set(leaseKey(l.ID), data)
+}
+
+// 0表示不关联lease，总是存在
+func (w *writes) leaseExists(id int64) bool {
+	if id == 0 {
+		return true
+	}
+	_, ok := w.lease(id)
+	return ok
+}
+
+// 把key关联到lease，id为0时解除key关联的lease。调用者需要先检查lease存在
+func (w *writes) attach(key string, id int64) {
+	var old int64
+	if data, ok := w.lookup(keyLeaseKey(key)); ok {
+		old, _ = strconv.ParseInt(string(data), 10, 64)
+	}
+	if old == id {
+		return
+	}
+	if l, ok := w.lease(old); ok {
+		delete(l.Keys, key)
+		w.saveLease(l)
+	}
+	if id == 0 {
+		w.remove(keyLeaseKey(key))
+		return
+	}
+	l, _ := w.lease(id)
+	if l.Keys == nil {
+		l.Keys = make(map[string]bool)
+	}
+	l.Keys[key] = true
+	w.saveLease(l)
+	w.set(keyLeaseKey(key), []byte(strconv.FormatInt(id, 10)))
+}
+
+func (s *Store) executeLease(op config.Op, index int64, w *writes) Reply {
+	if op.Option == OpLeaseGrant {
+		if op.TTL <= 0 {
+			return Reply{Err: "invalid lease TTL"}
+		}
+		l := &lease{ID: index, TTL: op.TTL, Expiry: op.Time + op.TTL}
+		w.saveLease(l)
+		util.DPrintf("LeaseGrant lease: %v, ttl: %v", l.ID, l.TTL)
+		return Reply{Lease: l.ID, TTL: l.TTL}
+	}
+	l, ok := w.lease(op.Lease)
+	if !ok {
+		return Reply{Err: ErrLeaseNotFound}
+	}
+	switch op.Option {
+	case OpLeaseKeepAlive:
+		if expiry := op.Time + l.TTL; expiry > l.Expiry {
+			l.Expiry = expiry
+		}
+		w.saveLease(l)
+		return Reply{Lease: l.ID, TTL: l.TTL}
+	case OpLeaseExpire:
+		if l.Expiry > op.Time {
+			// leader提议之后lease又被续约
+			return Reply{Lease: l.ID, TTL: l.TTL}
+		}
+	}
+	util.DPrintf("%v lease: %v, keys: %v", op.Option, l.ID, len(l.Keys))
+	for key := range l.Keys {
+		w.delete(key, index)
+	}
+	w.remove(leaseKey(l.ID))
+	return Reply{Lease: l.ID}
+}
+
+// Leases returns every granted lease, for the leader to find expired ones.
+func (s *Store) Leases() []LeaseInfo {
+	var leases []LeaseInfo
+	s.persister.ScanPrefix(leasePrefix, func(key string, value []byte) {
+		var l lease
+		if err := json.Unmarshal(value, &l); err == nil {
+			leases = append(leases, LeaseInfo{ID: l.ID, TTL: l.TTL, Expiry: l.Expiry})
+		}
+	})
+	return leases
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	pst "hckvstore/persister"
+	"hckvstore/util"
+)
+
+// 分片迁移：配置N把shard从组A分给组B时，
+//  1. A apply配置N，shard变为Frozen：不再执行访问它的命令，数据保留在A中不再修改；
+//  2. B apply配置N，shard变为Pulling；B的leader通过PullShard从A拉取shard的数据和A的去重表，
+//     A只在apply了配置N之后才返回数据，保证数据不会再变化；
+//  3. B把数据作为OpInstallShard提交到自己的日志，apply之后shard变为Serving；
+//     合并的去重表保证在A执行过的请求重试到B时不会再次执行；
+//  4. B的leader通知A删除shard，A提交OpDeleteShard删除数据，B收到确认之后提交OpShardDeleted。
+// 两个组都完成之后才会apply下一个配置。迁移过程中client收到ErrWrongGroup或ErrShardNotReady，重试即可。
+//
+// 迁移的key在B中的版本是安装它的日志下标，历史版本不迁移；lease属于授予它的组，迁移的key不再关联lease。
+
+const (
+	OpInstallShard = "InstallShard"
+	OpDeleteShard  = "DeleteShard"
+	OpShardDeleted = "ShardDeleted"
+)
+
+// PullShard请求的配置已经完成迁移，请求方的状态已经过时
+const ErrStaleShard = "stale shard request"
+
+// PullShard返回的shard数据
+type shardData struct {
+	Kvs      map[string]string
+	Sessions map[int64]session
+}
+
+// PendingShard is a migration step the leader of a group has to drive:
+// pull shard Shard of configuration Num from group Group, or tell Group
+// that the pulled shard has been installed and can be deleted.
+type PendingShard struct {
+	Shard   int
+	Num     int64
+	Group   int64
+	Servers []string
+	Pull    bool
+}
+
+// PendingShards returns the migration steps of the latest configuration
+// that are not done yet.
+func (s *Store) PendingShards() []PendingShard {
+	st := readShardState(s.persister.Lookup)
+	var pending []PendingShard
+	for shard, state := range st.Shards {
+		if state != shardPulling && !st.Notify[shard] {
+			continue
+		}
+		gid := st.Prev.Shards[shard]
+		pending = append(pending, PendingShard{
+			Shard:   shard,
+			Num:     st.Config.Num,
+			Group:   gid,
+			Servers: st.Prev.Groups[gid],
+			Pull:    state == shardPulling,
+		})
+	}
+	return pending
+}
+
+// 遍历shard中的所有用户key
+func scanShard(r pst.Reader, shard int, fn func(key string, value []byte)) {
+	r.Scan("", "", false, func(key string, value []byte) bool {
+		if shardctrler.Key2Shard(key) == shard {
+			fn(key, value)
+		}
+		return true
+	})
+}
+
+// ExportShard returns the data of shard, frozen by configuration num, and
+// the sessions of this group. It fails with ErrShardNotReady if num has not
+// been applied yet.
+func (s *Store) ExportShard(num int64, shard int) (data []byte, err string) {
+	if shard < 0 || shard >= shardctrler.NShards {
+		return nil, "invalid shard"
+	}
+	s.persister.View(func(r pst.Reader) {
+		st := readShardState(r.Lookup)
+		if st.Config.Num < num {
+			err = shardctrler.ErrShardNotReady
+			return
+		}
+		if st.Config.Num > num || st.Shards[shard] != shardFrozen {
+			err = ErrStaleShard
+			return
+		}
+		d := shardData{Kvs: make(map[string]string), Sessions: make(map[int64]session)}
+		scanShard(r, shard, func(key string, value []byte) {
+			d.Kvs[key] = string(value)
+		})
+		// 会话不属于某个shard，全部发送给新的组，由它按Seq合并
+		s.persister.ScanPrefix(sessionPrefix, func(key string, value []byte) {
+			var sess session
+			id, err := strconv.ParseInt(strings.TrimPrefix(key, sessionPrefix), 10, 64)
+			if err == nil && json.Unmarshal(value, &sess) == nil {
+				d.Sessions[id] = sess
+			}
+		})
+		data, _ = json.Marshal(d)
+	})
+	return data, err
+}
+
+func (s *Store) migrate(op config.Op, index int64, w *writes) Reply {
+	st := readShardState(w.lookup)
+	shard := int(op.Shard)
+	if op.ConfigNum != st.Config.Num || shard < 0 || shard >= shardctrler.NShards {
+		// 过时或者重复的命令
+		return Reply{Version: st.Config.Num}
+	}
+	switch op.Option {
+	case OpInstallShard:
+		if st.Shards[shard] != shardPulling {
+			return Reply{Version: st.Config.Num}
+		}
+		var d shardData
+		if err := json.Unmarshal([]byte(op.Value), &d); err != nil {
+			return Reply{Err: "invalid shard data"}
+		}
+		s.installShard(d, index, w)
+		st.Shards[shard], st.Notify[shard] = shardServing, true
+	case OpDeleteShard:
+		if st.Shards[shard] != shardFrozen {
+			return Reply{Version: st.Config.Num}
+		}
+		s.persister.View(func(r pst.Reader) {
+			scanShard(r, shard, func(key string, value []byte) {
+				w.remove(key)
+				w.remove(versionKey(key))
+			})
+		})
+		s.persister.DropRevisions(&w.batch, func(key string) bool {
+			return shardctrler.Key2Shard(key) == shard
+		})
+		st.Shards[shard] = ""
+	case OpShardDeleted:
+		st.Notify[shard] = false
+	}
+	util.DPrintf("%v group: %v, config: %v, shard: %v", op.Option, s.gid, op.ConfigNum, shard)
+	w.saveShardState(st)
+	return Reply{Version: st.Config.Num}
+}
+
+// 写入shard的数据并合并去重表。key按顺序写入，所有副本产生相同顺序的事件
+func (s *Store) installShard(d shardData, index int64, w *writes) {
+	keys := make([]string, 0, len(d.Kvs))
+	for key := range d.Kvs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		w.put(key, []byte(d.Kvs[key]), index)
+	}
+	for id, sess := range d.Sessions {
+		var cur session
+		if data, ok := w.lookup(sessionKey(id)); ok && json.Unmarshal(data, &cur) == nil && cur.Seq >= sess.Seq {
+			continue
+		}
+		data, _ := json.Marshal(sess)
+		w.set(sessionKey(id), data)
+	}
+}
+
+// 离开本组的shard中的key不再关联lease，lease被撤销时不会修改已经冻结的数据
+func (s *Store) detachShards(shards []int, w *writes) {
+	if len(shards) == 0 {
+		return
+	}
+	leaving := make(map[int]bool)
+	for _, shard := range shards {
+		leaving[shard] = true
+	}
+	var keys []string
+	s.persister.ScanPrefix(keyLeasePrefix, func(key string, value []byte) {
+		if key := strings.TrimPrefix(key, keyLeasePrefix); leaving[shardctrler.Key2Shard(key)] {
+			keys = append(keys, key)
+		}
+	})
+	for _, key := range keys {
+		w.attach(key, 0)
+	}
+}
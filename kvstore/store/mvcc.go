@@ -0,0 +1,70 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+
+	"hckvstore/config"
+	pst "hckvstore/persister"
+	"hckvstore/util"
+)
+
+// 多版本读：persister为每次写入保存(key, revision)的版本，revision是日志下标。
+// Get和Scan可以读任意一个没有被压缩的revision时的数据；Compact是一条日志，
+// 所有副本在同一个位置删除compact revision之前的版本，之后读更早的revision返回CompactedError。
+
+const OpCompact = "Compact"
+
+const mvccCompactedKey = pst.ReservedPrefix + "kv/mvcc/compacted"
+
+// 读取的revision还没有apply
+var ErrFutureRevision = errors.New("revision is not applied yet")
+
+func readMVCCCompacted(lookup func(key string) ([]byte, bool)) int64 {
+	var compacted int64
+	if data, ok := lookup(mvccCompactedKey); ok {
+		json.Unmarshal(data, &compacted)
+	}
+	return compacted
+}
+
+// 检查rev是否可以读，调用者在同一个View中读取数据
+func (s *Store) checkRevision(r pst.Reader, rev int64) error {
+	if rev > s.lastApplied() {
+		return ErrFutureRevision
+	}
+	if compacted := readMVCCCompacted(r.Lookup); rev < compacted {
+		return &CompactedError{Revision: compacted}
+	}
+	return nil
+}
+
+// GetAt reads key as of revision rev. It returns the value and the revision
+// that wrote it, or an empty value and 0 if the key did not exist then.
+func (s *Store) GetAt(key string, rev int64) (value string, version int64, err error) {
+	s.persister.View(func(r pst.Reader) {
+		if err = s.checkRevision(r, rev); err != nil {
+			return
+		}
+		v, modRev, ok := r.GetAt(key, rev)
+		if ok {
+			value, version = string(v), modRev
+		}
+	})
+	return value, version, err
+}
+
+func (s *Store) compact(op config.Op, index int64, w *writes) Reply {
+	if op.Revision <= 0 || op.Revision > index {
+		return Reply{Err: "invalid compact revision"}
+	}
+	compacted := readMVCCCompacted(w.lookup)
+	if op.Revision <= compacted {
+		return Reply{Version: compacted}
+	}
+	util.DPrintf("Compact revision: %v", op.Revision)
+	s.persister.CompactRevisions(&w.batch, op.Revision)
+	data, _ := json.Marshal(op.Revision)
+	w.set(mvccCompactedKey, data)
+	return Reply{Version: op.Revision}
+}
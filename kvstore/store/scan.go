@@ -0,0 +1,114 @@
+package store
+
+import (
+	pst "hckvstore/persister"
+)
+
+// Scan按key的顺序(或者逆序)读取一个范围内的key，每次最多返回Limit个，
+// 没有读完时返回NextToken，下一次带上这个token从上一页结束的位置继续。
+// 每一页都从同一个LevelDB快照中读出，不会混合不同apply下标的状态；不同的页之间可能有新的写入。
+// 设置Revision时读取这个revision时的数据，所有的页读到的都是同一个revision。
+
+const (
+	DefaultScanLimit = 100
+	MaxScanLimit     = 1000
+)
+
+// ScanOptions describes one page of a scan. Prefix, if set, replaces Start
+// and End with the range of keys that start with Prefix.
+type ScanOptions struct {
+	Start    string
+	End      string // 不包含End，""表示没有上界
+	Prefix   string
+	Limit    int
+	Reverse  bool
+	KeysOnly bool
+	Token    []byte // 上一页返回的NextToken
+	Revision int64  // 大于0时读取这个revision时的数据
+	// 分片时大于0：本组apply的配置必须是这个配置，并且没有正在拉取的shard，
+	// 跨组的Scan中每个组按同一个配置过滤key，合并之后不会重复或者遗漏
+	ConfigNum int64
+}
+
+type KeyValue struct {
+	Key     string
+	Value   string
+	Version int64
+}
+
+// Scan returns one page of the range described by opts and the token of the
+// next page, or nil when the range is exhausted. A scan at a revision fails
+// with *CompactedError or ErrFutureRevision if that revision cannot be read,
+// and a scan at a configuration fails if the group is not at ConfigNum.
+func (s *Store) Scan(opts ScanOptions) (kvs []KeyValue, next []byte, err error) {
+	start, end := opts.Start, opts.End
+	if opts.Prefix != "" {
+		start, end = opts.Prefix, prefixEnd(opts.Prefix)
+	}
+	// 正序时token是下一页的第一个key，逆序时token是上一页的最后一个key，作为下一页的上界
+	if len(opts.Token) > 0 {
+		if opts.Reverse {
+			end = string(opts.Token)
+		} else {
+			start = string(opts.Token)
+		}
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultScanLimit
+	}
+	if limit > MaxScanLimit {
+		limit = MaxScanLimit
+	}
+
+	var st shardState
+	visit := func(key string, value []byte, version int64) bool {
+		if !s.owns(st, key) {
+			// 分片时只返回本组负责的shard中的key
+			return true
+		}
+		if len(kvs) == limit {
+			// 还有更多的key
+			if opts.Reverse {
+				next = []byte(kvs[len(kvs)-1].Key)
+			} else {
+				next = []byte(key)
+			}
+			return false
+		}
+		kv := KeyValue{Key: key, Version: version}
+		if !opts.KeysOnly {
+			kv.Value = string(value)
+		}
+		kvs = append(kvs, kv)
+		return true
+	}
+	s.persister.View(func(r pst.Reader) {
+		st = readShardState(r.Lookup)
+		if err = s.checkConfig(st, opts.ConfigNum); err != nil {
+			return
+		}
+		if opts.Revision > 0 {
+			if err = s.checkRevision(r, opts.Revision); err == nil {
+				r.ScanAt(start, end, opts.Revision, opts.Reverse, visit)
+			}
+			return
+		}
+		r.Scan(start, end, opts.Reverse, func(key string, value []byte) bool {
+			return visit(key, value, readVersion(r.Lookup, key))
+		})
+	})
+	return kvs, next, err
+}
+
+// 返回大于所有以prefix开头的key的最小key，""表示没有上界
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"hckvstore/config"
+	pst "hckvstore/persister"
+)
+
+// 客户端会话(去重表)：每个客户端(Op.Id)记录最近一次执行的Seq和执行结果。
+// client对每个新请求递增Seq，超时重试时使用相同的Seq，所以Seq不大于记录的Seq的命令
+// 都是重复的请求，不再执行，Seq相同时返回第一次执行的结果。
+// 会话保存在LevelDB的保留key下，和命令修改的数据在同一个batch中写入，并且包含在快照中。
+//
+// 超过sessionTTL没有新请求的会话过期，之后这个客户端的重复请求不再能被识别。
+// 过期按日志中leader写入的Op.Time判断而不是本地时钟，所有副本对同一条命令的判断相同。
+
+const sessionPrefix = pst.ReservedPrefix + "kv/session/"
+
+// 下一次清理过期会话的Op.Time，和会话一起复制，所有副本在同一条命令上清理
+const sessionExpireKey = pst.ReservedPrefix + "kv/session-expire"
+
+// DefaultSessionTTL is how long a client session is kept without requests.
+const DefaultSessionTTL = 10 * time.Minute
+
+// 客户端已经发出了更新的请求，不会再等待这个请求的结果
+const ErrStaleRequest = "stale request"
+
+type session struct {
+	Seq        int64
+	Reply      Reply
+	LastActive int64 // 最近一次执行的命令的Op.Time
+}
+
+func sessionKey(id int64) string {
+	return sessionPrefix + strconv.FormatInt(id, 10)
+}
+
+func (s *Store) loadSession(id int64) (session, bool) {
+	var sess session
+	data, ok := s.persister.Lookup(sessionKey(id))
+	if !ok {
+		return sess, false
+	}
+	if err := json.Unmarshal(data, &sess); err != nil {
+		log.Fatalln("decode session failed: ", err)
+	}
+	return sess, true
+}
+
+func (s *Store) expired(sess session, now int64) bool {
+	return s.sessionTTL > 0 && time.Duration(now-sess.LastActive)*time.Millisecond > s.sessionTTL
+}
+
+// 返回op是否是已经执行过的请求以及第一次执行的结果，否则返回op所属的会话
+func (s *Store) duplicate(op config.Op) (Reply, session, bool) {
+	if op.Id == 0 {
+		// 不属于任何客户端会话
+		return Reply{}, session{}, false
+	}
+	sess, ok := s.loadSession(op.Id)
+	if !ok || s.expired(sess, op.Time) {
+		return Reply{}, session{}, false
+	}
+	if op.Seq < sess.Seq {
+		return Reply{Err: ErrStaleRequest}, sess, true
+	}
+	if op.Seq == sess.Seq {
+		return sess.Reply, sess, true
+	}
+	return Reply{}, sess, false
+}
+
+// 记录op的执行结果，和op的修改写入同一个batch
+func (s *Store) saveSession(batch *pst.Batch, op config.Op, sess session, reply Reply) {
+	if op.Id == 0 {
+		return
+	}
+	sess.Seq = op.Seq
+	sess.Reply = reply
+	// leader切换之后新leader的时钟可能落后，LastActive不后退
+	if op.Time > sess.LastActive {
+		sess.LastActive = op.Time
+	}
+	data, _ := json.Marshal(sess)
+	batch.Put(sessionKey(op.Id), data)
+}
+
+// 每隔一个sessionTTL删除过期的会话，删除和下一次清理的时间写入命令的batch。
+// 清理的时间保存在LevelDB中而不是内存里，重启或者安装快照之后各个副本仍然在同一条命令上清理，
+// LevelDB中的数据和快照在所有副本上相同
+func (s *Store) expireSessions(batch *pst.Batch, now int64) {
+	if s.sessionTTL <= 0 {
+		return
+	}
+	if data, ok := s.persister.Lookup(sessionExpireKey); ok {
+		next, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			log.Fatalln("decode session expire time failed: ", err)
+		}
+		if now < next {
+			return
+		}
+	}
+	next := now + int64(s.sessionTTL/time.Millisecond)
+	batch.Put(sessionExpireKey, []byte(strconv.FormatInt(next, 10)))
+	s.persister.ScanPrefix(sessionPrefix, func(key string, value []byte) {
+		var sess session
+		if err := json.Unmarshal(value, &sess); err == nil && s.expired(sess, now) {
+			batch.Delete(key)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	pst "hckvstore/persister"
+	"hckvstore/util"
+)
+
+// 分片：多个复制组各自负责一部分shard(见shardctrler)。复制组的leader向shard controller
+// 依次查询下一个配置，作为OpConfig提交到日志，所有副本在同一个日志位置切换负责的shard。
+// 配置变化时shard在组之间迁移(见migrate.go)，迁移完成之前不会apply下一个配置。
+// 访问不由本组提供服务的key的命令不执行，返回ErrWrongGroup或者ErrShardNotReady，也不记录到会话中：
+// client用相同的Seq重试，这个请求可能会被发给另一个组，也可能在配置再次变化之后回到本组。
+// gid为0的Store不分片，负责所有的key。
+
+// Value为JSON编码的shardctrler.Config，编号必须是当前配置的下一个，否则忽略
+const OpConfig = "Config"
+
+const shardStateKey = pst.ReservedPrefix + "kv/shard/state"
+
+// 本组中一个shard的状态，""表示不由本组负责
+const (
+	shardServing = "Serving"
+	shardPulling = "Pulling" // 新分给本组，等待从原来的组拉取数据
+	shardFrozen  = "Frozen"  // 分给了其他组，数据保留到新的组拉取之后
+)
+
+type shardState struct {
+	Config shardctrler.Config
+	Prev   shardctrler.Config // 上一个配置，Pulling的shard从其中的组拉取
+	Shards [shardctrler.NShards]string
+	// shard已经安装，原来的组还没有确认删除数据
+	Notify [shardctrler.NShards]bool
+}
+
+func readShardState(lookup func(key string) ([]byte, bool)) shardState {
+	var st shardState
+	if data, ok := lookup(shardStateKey); ok {
+		json.Unmarshal(data, &st)
+	}
+	return st
+}
+
+func (w *writes) saveShardState(st shardState) {
+	data, _ := json.Marshal(st)
+	w.set(shardStateKey, data)
+}
+
+// 迁移是否已经完成，完成之后才能apply下一个配置
+func (st shardState) stable() bool {
+	for shard, state := range st.Shards {
+		if state == shardPulling || state == shardFrozen || st.Notify[shard] {
+			return false
+		}
+	}
+	return true
+}
+
+// 返回本组能否为key提供服务，不能时返回client应该看到的错误
+func (s *Store) checkKey(st shardState, key string) string {
+	if s.gid == 0 {
+		return ""
+	}
+	switch st.Shards[shardctrler.Key2Shard(key)] {
+	case shardServing:
+		return ""
+	case shardPulling:
+		return shardctrler.ErrShardNotReady
+	}
+	return shardctrler.ErrWrongGroup
+}
+
+// 检查本组apply的配置是否是num并且没有正在拉取的shard，num为0时不检查
+func (s *Store) checkConfig(st shardState, num int64) error {
+	if s.gid == 0 || num == 0 {
+		return nil
+	}
+	if st.Config.Num > num {
+		return errors.New(shardctrler.ErrWrongGroup)
+	}
+	if st.Config.Num < num {
+		return errors.New(shardctrler.ErrShardNotReady)
+	}
+	for _, state := range st.Shards {
+		if state == shardPulling {
+			return errors.New(shardctrler.ErrShardNotReady)
+		}
+	}
+	return nil
+}
+
+func (s *Store) owns(st shardState, key string) bool {
+	return s.checkKey(st, key) == ""
+}
+
+// op读写的所有用户key
+func opKeys(op config.Op) []string {
+	switch op.Option {
+	case "Put", "Append", "Delete", "Get", OpCASValue, OpCASVersion, OpPutIfAbsent, OpPutIfPresent:
+		return []string{op.Key}
+	case OpTxn:
+		var keys []string
+		for _, c := range op.Compares {
+			keys = append(keys, c.Key)
+		}
+		for _, sub := range append(append([]config.Op{}, op.Success...), op.Failure...) {
+			keys = append(keys, sub.Key)
+		}
+		return keys
+	}
+	return nil
+}
+
+func (s *Store) checkOp(op config.Op) string {
+	if s.gid == 0 {
+		return ""
+	}
+	st := readShardState(s.persister.Lookup)
+	for _, key := range opKeys(op) {
+		if err := s.checkKey(st, key); err != "" {
+			return err
+		}
+	}
+	return ""
+}
+
+// CheckKey returns "" if this group serves key in the latest applied
+// configuration, or the error a client should retry on: ErrWrongGroup if
+// key belongs to another group, ErrShardNotReady if its shard is still
+// being moved to this group.
+func (s *Store) CheckKey(key string) string {
+	return s.checkKey(readShardState(s.persister.Lookup), key)
+}
+
+// Config returns the latest configuration applied by this group.
+func (s *Store) Config() shardctrler.Config {
+	return readShardState(s.persister.Lookup).Config
+}
+
+// Migrating reports whether shards of the latest configuration are still
+// moving in or out of this group, which holds back the next configuration.
+func (s *Store) Migrating() bool {
+	return !readShardState(s.persister.Lookup).stable()
+}
+
+func (s *Store) applyConfig(op config.Op, w *writes) Reply {
+	var cfg shardctrler.Config
+	if err := json.Unmarshal([]byte(op.Value), &cfg); err != nil {
+		return Reply{Err: "invalid config"}
+	}
+	st := readShardState(w.lookup)
+	if cfg.Num != st.Config.Num+1 || !st.stable() {
+		// leader重复提交的配置，或者上一个配置的迁移还没有完成
+		return Reply{Version: st.Config.Num}
+	}
+	var frozen []int
+	for shard := range st.Shards {
+		owned := st.Shards[shard] == shardServing
+		switch {
+		case cfg.Shards[shard] == s.gid && !owned && st.Config.Shards[shard] != 0:
+			st.Shards[shard] = shardPulling
+		case cfg.Shards[shard] == s.gid:
+			// 之前没有分配的shard没有数据，直接提供服务
+			st.Shards[shard] = shardServing
+		case owned && cfg.Shards[shard] != 0:
+			st.Shards[shard] = shardFrozen
+			frozen = append(frozen, shard)
+		default:
+			// 所有组都离开之后没有分配的shard，数据不再迁移
+			st.Shards[shard] = ""
+		}
+	}
+	s.detachShards(frozen, w)
+	st.Prev, st.Config = st.Config, cfg
+	util.DPrintf("Config group: %v, config: %v, shards: %v", s.gid, cfg.Num, st.Shards)
+	w.saveShardState(st)
+	return Reply{Version: cfg.Num}
+}
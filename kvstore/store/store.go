@@ -0,0 +1,159 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"hckvstore/config"
+	pst "hckvstore/persister"
+	"hckvstore/raft"
+	"hckvstore/util"
+)
+
+// Store是KV服务的状态机，实现raft.StateMachine：
+// 日志中的命令是protobuf编码的config.Op，数据保存在和Raft共用的LevelDB中。
+// Raft同步写持久化状态时会把之前apply的数据一起刷到磁盘，快照就是LevelDB中的全部用户数据和会话。
+type Store struct {
+	persister  *pst.Persister
+	gid        int64         // 复制组，0表示不分片
+	sessionTTL time.Duration // 客户端会话的过期时间，0表示不过期
+
+	mu       sync.Mutex
+	applied  int64 // 最近一次apply的日志下标，和修改一起持久化，事件已经写入LevelDB并通知了watcher
+	watchers map[*Watcher]bool
+}
+
+func MakeStore(persister *pst.Persister, sessionTTL time.Duration) *Store {
+	return MakeGroupStore(persister, sessionTTL, 0)
+}
+
+// MakeGroupStore returns the state machine of replica group gid, which only
+// serves the shards assigned to gid by the applied configurations.
+func MakeGroupStore(persister *pst.Persister, sessionTTL time.Duration, gid int64) *Store {
+	return &Store{persister: persister, gid: gid, sessionTTL: sessionTTL, applied: persister.Applied(), watchers: make(map[*Watcher]bool)}
+}
+
+// Reply是一条命令的执行结果，作为Apply的返回值交给leader上等待的Proposal。
+// 客户端重试的命令不会再次执行，而是返回第一次执行时保存的Reply
+type Reply struct {
+	Err   string `json:",omitempty"`
+	Value string `json:",omitempty"`
+	// 条件写入的条件不成立，Value和Version是key当前的值和版本
+	Mismatch bool  `json:",omitempty"`
+	Version  int64 `json:",omitempty"`
+	// Txn中每个执行的操作的结果
+	Results []Reply `json:",omitempty"`
+	// lease操作的lease和有效期(毫秒)
+	Lease int64 `json:",omitempty"`
+	TTL   int64 `json:",omitempty"`
+}
+
+// 命令的Option不是Store支持的操作
+const ErrUnknownOp = "unknown op"
+
+func (s *Store) Apply(msg raft.ApplyMsg) interface{} {
+	index := int64(msg.CommandIndex)
+	if index <= s.lastApplied() {
+		// 重启之后Raft从快照的位置重新apply日志，修改已经写入LevelDB的日志不再执行
+		return nil
+	}
+	w := s.newWrites()
+	var reply interface{}
+	if msg.CommandValid {
+		reply = s.applyCommand(msg, index, w)
+	}
+	// 命令的所有修改、事件、会话和apply到的下标写入同一个batch，apply的过程中崩溃不会留下一半的修改
+	s.saveEvents(w, index)
+	w.batch.SetApplied(index)
+	s.persister.Write(&w.batch)
+	s.publish(index, w.events)
+	return reply
+}
+
+// 解码并执行第index条日志中的命令，修改写入w
+func (s *Store) applyCommand(msg raft.ApplyMsg, index int64, w *writes) interface{} {
+	op, err := config.DecodeOp(msg.Command)
+	if err != nil {
+		// 不是KV命令
+		util.DPrintf("decode op failed: %v, index: %v", err, msg.CommandIndex)
+		return nil
+	}
+	if err := s.checkOp(op); err != "" {
+		return Reply{Err: err}
+	}
+	reply, sess, dup := s.duplicate(op)
+	if dup {
+		util.DPrintf("duplicate request, client: %v, seq: %v", op.Id, op.Seq)
+		return reply
+	}
+	// 先删除过期的会话，op所属的会话过期之后重新保存
+	s.expireSessions(&w.batch, op.Time)
+	reply = s.execute(op, index, w)
+	s.saveSession(&w.batch, op, sess, reply)
+	return reply
+}
+
+// 执行第index条日志中的op，修改写入w。
+// apply是串行的，读出旧值再写入新值的过程中不会有其他命令修改这个key
+func (s *Store) execute(op config.Op, index int64, w *writes) Reply {
+	switch op.Option {
+	case "Put":
+		if !w.leaseExists(op.Lease) {
+			return Reply{Err: ErrLeaseNotFound}
+		}
+		w.put(op.Key, []byte(op.Value), index)
+		w.attach(op.Key, op.Lease)
+	case "Append":
+		old, _ := w.lookup(op.Key)
+		w.put(op.Key, append(old, op.Value...), index)
+	case "Delete":
+		w.delete(op.Key, index)
+		return Reply{}
+	case "Get":
+		// 只在Txn中出现，读到同一个Txn中之前的写入
+		value, _ := w.lookup(op.Key)
+		return Reply{Value: string(value), Version: w.version(op.Key)}
+	case OpCASValue, OpCASVersion, OpPutIfAbsent, OpPutIfPresent:
+		return s.compareAndSwap(op, index, w)
+	case OpTxn:
+		return s.txn(op, index, w)
+	case OpLeaseGrant, OpLeaseKeepAlive, OpLeaseRevoke, OpLeaseExpire:
+		return s.executeLease(op, index, w)
+	case OpCompact:
+		return s.compact(op, index, w)
+	case OpConfig:
+		return s.applyConfig(op, w)
+	case OpInstallShard, OpDeleteShard, OpShardDeleted:
+		return s.migrate(op, index, w)
+	default:
+		return Reply{Err: ErrUnknownOp}
+	}
+	return Reply{Version: index}
+}
+
+func (s *Store) Snapshot() []byte {
+	return s.persister.Snapshot()
+}
+
+func (s *Store) Restore(index int32, snapshot []byte) {
+	s.persister.RestoreSnapshot(snapshot, int64(index))
+	s.restored(int64(index))
+}
+
+// Get reads key from the local state machine. A missing or deleted key
+// reads as "".
+func (s *Store) Get(key string) string {
+	value, _ := s.persister.Lookup(key)
+	return string(value)
+}
+
+// GetVersion reads key and its version from the same applied state. The
+// version of a missing key is 0.
+func (s *Store) GetVersion(key string) (value string, version int64) {
+	s.persister.View(func(r pst.Reader) {
+		v, _ := r.Lookup(key)
+		value = string(v)
+		version = readVersion(r.Lookup, key)
+	})
+	return value, version
+}
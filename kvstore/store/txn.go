@@ -0,0 +1,94 @@
+package store
+
+import "hckvstore/config"
+
+// Txn是一条日志：所有比较条件都成立时依次执行Success中的操作，否则执行Failure中的操作。
+// 比较和操作在同一次apply中完成，所有修改写入同一个batch，要么全部生效，要么都不生效。
+
+const OpTxn = "Txn"
+
+// Txn中有不支持的比较或者操作，整个Txn不执行
+const ErrInvalidTxn = "invalid txn"
+
+// Txn中可以执行的操作
+var txnOps = map[string]bool{"Put": true, "Append": true, "Delete": true, "Get": true}
+
+func (s *Store) txn(op config.Op, index int64, w *writes) Reply {
+	if !validTxn(op) {
+		return Reply{Err: ErrInvalidTxn}
+	}
+	// Txn中的写入要么都执行要么都不执行，lease不存在时整个Txn不执行
+	for _, sub := range append(append([]config.Op{}, op.Success...), op.Failure...) {
+		if !w.leaseExists(sub.Lease) {
+			return Reply{Err: ErrLeaseNotFound}
+		}
+	}
+	ops := op.Success
+	matched := true
+	for _, c := range op.Compares {
+		if !compare(w, c) {
+			matched = false
+			ops = op.Failure
+			break
+		}
+	}
+	reply := Reply{Mismatch: !matched, Results: []Reply{}}
+	for _, sub := range ops {
+		reply.Results = append(reply.Results, s.execute(sub, index, w))
+	}
+	return reply
+}
+
+func validTxn(op config.Op) bool {
+	for _, c := range op.Compares {
+		if c.Target != "Value" && c.Target != "Version" {
+			return false
+		}
+		switch c.Result {
+		case "=", "!=", ">", "<":
+		default:
+			return false
+		}
+	}
+	for _, sub := range append(append([]config.Op{}, op.Success...), op.Failure...) {
+		if !txnOps[sub.Option] {
+			return false
+		}
+	}
+	return true
+}
+
+// 比较值时key不存在则条件不成立，比较版本时不存在的key版本为0
+func compare(w *writes, c config.Compare) bool {
+	var cmp int
+	if c.Target == "Value" {
+		value, ok := w.lookup(c.Key)
+		if !ok {
+			return false
+		}
+		switch {
+		case string(value) < c.Value:
+			cmp = -1
+		case string(value) > c.Value:
+			cmp = 1
+		}
+	} else {
+		version := w.version(c.Key)
+		switch {
+		case version < c.Version:
+			cmp = -1
+		case version > c.Version:
+			cmp = 1
+		}
+	}
+	switch c.Result {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp < 0
+	}
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	pst "hckvstore/persister"
+)
+
+// Watch：apply时每个用户key的修改产生一个事件，Revision为修改它的日志下标。
+// 同一条日志(例如一个Txn)的所有事件一起交给watcher，watcher按提交顺序收到事件。
+//
+// 最近watchHistory条日志的事件保存在LevelDB的保留key下，和数据在同一个batch中写入并包含在快照中，
+// watcher可以从其中任意一个revision开始恢复；更早的事件已经被压缩，Watch返回CompactedError。
+
+// 保留的事件历史覆盖的日志条数
+const watchHistory = 10000
+
+// watcher没有及时读取的事件最多缓存的日志条数
+const watchBuffer = 1024
+
+const (
+	eventPrefix  = pst.ReservedPrefix + "kv/watch/event/"
+	compactedKey = pst.ReservedPrefix + "kv/watch/compacted"
+)
+
+const (
+	EventPut    = "Put"
+	EventDelete = "Delete"
+)
+
+type Event struct {
+	Type     string
+	Key      string
+	Value    string `json:",omitempty"`
+	Revision int64
+}
+
+// watcher读取得太慢或者节点安装了快照，之后的事件不会再交给它，需要从下一个revision重新Watch
+var ErrWatchCanceled = errors.New("watch canceled, resume from the next revision")
+
+// CompactedError说明请求的revision已经不在保留的事件历史中
+type CompactedError struct {
+	Revision int64 // 已经压缩的最大revision
+}
+
+func (e *CompactedError) Error() string {
+	return fmt.Sprintf("revision %d has been compacted", e.Revision)
+}
+
+type Watcher struct {
+	store   *Store
+	key     string
+	prefix  bool
+	start   int64     // 只返回Revision不小于start的事件
+	backlog [][]Event // 注册之前的历史事件，先于ch中的事件返回
+	ch      chan []Event
+	err     error // ch关闭的原因，由store.mu保护
+}
+
+// Watch registers a watcher for key, or for every key starting with key if
+// prefix is set. It returns the last applied revision: later events are
+// delivered as they are applied, and if start > 0 the retained events from
+// start up to that revision are replayed first. A start older than the
+// retained history fails with *CompactedError.
+func (s *Store) Watch(key string, prefix bool, start int64) (*Watcher, int64, error) {
+	w := &Watcher{store: s, key: key, prefix: prefix, start: start, ch: make(chan []Event, watchBuffer)}
+	s.mu.Lock()
+	revision := s.applied
+	s.watchers[w] = true
+	s.mu.Unlock()
+	if start <= 0 || start > revision {
+		return w, revision, nil
+	}
+
+	// revision及之前的事件都已经写入LevelDB
+	var compacted int64
+	s.persister.View(func(r pst.Reader) {
+		compacted = readCompacted(r.Lookup)
+		if start <= compacted {
+			return
+		}
+		for index := start; index <= revision; index++ {
+			events := readEvents(r.Lookup, index)
+			if events = w.match(events); len(events) > 0 {
+				w.backlog = append(w.backlog, events)
+			}
+		}
+	})
+	if start <= compacted {
+		w.Close()
+		return nil, revision, &CompactedError{Revision: compacted}
+	}
+	return w, revision, nil
+}
+
+// Next blocks until the next batch of events that match the watcher, ctx is
+// done, or the watcher is canceled with ErrWatchCanceled.
+func (w *Watcher) Next(ctx context.Context) ([]Event, error) {
+	if len(w.backlog) > 0 {
+		events := w.backlog[0]
+		w.backlog = w.backlog[1:]
+		return events, nil
+	}
+	select {
+	case events, ok := <-w.ch:
+		if !ok {
+			w.store.mu.Lock()
+			defer w.store.mu.Unlock()
+			return nil, w.err
+		}
+		return events, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (w *Watcher) Close() {
+	w.store.mu.Lock()
+	defer w.store.mu.Unlock()
+	w.store.cancelWatcher(w, ErrWatchCanceled)
+}
+
+func (w *Watcher) match(events []Event) []Event {
+	var res []Event
+	for _, e := range events {
+		if e.Revision < w.start {
+			continue
+		}
+		if e.Key == w.key || w.prefix && strings.HasPrefix(e.Key, w.key) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// 调用者需要持有s.mu
+func (s *Store) cancelWatcher(w *Watcher, err error) {
+	if !s.watchers[w] {
+		return
+	}
+	delete(s.watchers, w)
+	w.err = err
+	close(w.ch)
+}
+
+func (s *Store) lastApplied() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.applied
+}
+
+// 第index条日志apply完成，把它的事件交给watcher。缓存满了的watcher被取消，不阻塞apply
+func (s *Store) publish(index int64, events []Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applied = index
+	if len(events) == 0 {
+		return
+	}
+	for w := range s.watchers {
+		matched := w.match(events)
+		if len(matched) == 0 {
+			continue
+		}
+		select {
+		case w.ch <- matched:
+		default:
+			s.cancelWatcher(w, ErrWatchCanceled)
+		}
+	}
+}
+
+// 安装快照跳过了中间的日志，这些修改没有通过publish交给watcher，所有watcher需要从快照中的历史恢复
+func (s *Store) restored(index int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applied = index
+	for w := range s.watchers {
+		s.cancelWatcher(w, ErrWatchCanceled)
+	}
+}
+
+func eventKey(index int64) string {
+	return fmt.Sprintf("%s%020d", eventPrefix, index)
+}
+
+func readEvents(lookup func(key string) ([]byte, bool), index int64) []Event {
+	var events []Event
+	if data, ok := lookup(eventKey(index)); ok {
+		if err := json.Unmarshal(data, &events); err != nil {
+			log.Fatalln("decode events failed: ", err)
+		}
+	}
+	return events
+}
+
+func readCompacted(lookup func(key string) ([]byte, bool)) int64 {
+	var compacted int64
+	if data, ok := lookup(compactedKey); ok {
+		json.Unmarshal(data, &compacted)
+	}
+	return compacted
+}
+
+// 把第index条日志的事件写入w的batch，历史超过2*watchHistory条日志时压缩到watchHistory条。
+// 压缩只依赖日志下标，所有副本压缩的位置相同
+func (s *Store) saveEvents(w *writes, index int64) {
+	if len(w.events) > 0 {
+		data, _ := json.Marshal(w.events)
+		w.batch.Put(eventKey(index), data)
+	}
+	compacted := readCompacted(s.persister.Lookup)
+	if index-compacted < 2*watchHistory {
+		return
+	}
+	compacted = index - watchHistory
+	s.persister.ScanPrefix(eventPrefix, func(key string, value []byte) {
+		if key <= eventKey(compacted) {
+			w.batch.Delete(key)
+		}
+	})
+	data, _ := json.Marshal(compacted)
+	w.batch.Put(compactedKey, data)
+}
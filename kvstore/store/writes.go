@@ -0,0 +1,60 @@
+package store
+
+import (
+	"strconv"
+
+	pst "hckvstore/persister"
+)
+
+// writes收集一条命令的所有修改，最后作为一个batch写入LevelDB。
+// 修改同时记录在pending中，Txn中后面的操作可以读到前面还没有写入LevelDB的修改
+type writes struct {
+	persister *pst.Persister
+	batch     pst.Batch
+	pending   map[string][]byte // nil表示已经删除
+	events    []Event           // 用户key的修改，按执行顺序
+}
+
+func (s *Store) newWrites() *writes {
+	return &writes{persister: s.persister, pending: make(map[string][]byte)}
+}
+
+func (w *writes) lookup(key string) ([]byte, bool) {
+	if value, ok := w.pending[key]; ok {
+		return value, value != nil
+	}
+	return w.persister.Lookup(key)
+}
+
+func (w *writes) version(key string) int64 {
+	return readVersion(w.lookup, key)
+}
+
+func (w *writes) set(key string, value []byte) {
+	w.batch.Put(key, value)
+	w.pending[key] = append([]byte{}, value...)
+}
+
+func (w *writes) remove(key string) {
+	w.batch.Delete(key)
+	w.pending[key] = nil
+}
+
+// 写入key的值，版本更新为index，同时保存这个revision的版本
+func (w *writes) put(key string, value []byte, index int64) {
+	w.set(key, value)
+	w.batch.PutRevision(key, index, value)
+	w.set(versionKey(key), []byte(strconv.FormatInt(index, 10)))
+	w.events = append(w.events, Event{Type: EventPut, Key: key, Value: string(value), Revision: index})
+}
+
+// 删除第index条日志中的key，版本回到0，解除关联的lease。删除不存在的key不产生事件
+func (w *writes) delete(key string, index int64) {
+	if _, ok := w.lookup(key); ok {
+		w.events = append(w.events, Event{Type: EventDelete, Key: key, Revision: index})
+		w.batch.DeleteRevision(key, index)
+	}
+	w.remove(key)
+	w.remove(versionKey(key))
+	w.attach(key, 0)
+}
@@ -0,0 +1,182 @@
+package persister
+
+import (
+	"encoding/binary"
+
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 多版本存储：每次写入除了覆盖key的最新值，还以(key, revision)为key保存一个版本，revision是Raft日志下标。
+// 版本的key为 mvccPrefix + escape(key) + 0x00 0x01 + 8字节大端revision：
+// key中的0x00转义为0x00 0xff，0x00 0x01作为结束符，编码之后的顺序和(key, revision)的顺序一致，
+// 同一个key的所有版本相邻并按revision升序排列。
+// 版本的value第一个字节区分写入和删除(tombstone)，删除之后在更大的revision上读不到这个key。
+
+const mvccPrefix = ReservedPrefix + "mvcc/"
+
+const (
+	versionPut    = 'p'
+	versionDelete = 'd'
+)
+
+func escapeKey(key string) []byte {
+	buf := make([]byte, 0, len(mvccPrefix)+len(key)+2)
+	buf = append(buf, mvccPrefix...)
+	for i := 0; i < len(key); i++ {
+		buf = append(buf, key[i])
+		if key[i] == 0x00 {
+			buf = append(buf, 0xff)
+		}
+	}
+	return buf
+}
+
+func mvccKey(key string, rev int64) []byte {
+	buf := append(escapeKey(key), 0x00, 0x01)
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], uint64(rev))
+	return append(buf, r[:]...)
+}
+
+// 解码版本的key，返回用户key和revision
+func decodeMVCCKey(k []byte) (string, int64) {
+	k = k[len(mvccPrefix):]
+	rev := int64(binary.BigEndian.Uint64(k[len(k)-8:]))
+	k = k[:len(k)-10]
+	key := make([]byte, 0, len(k))
+	for i := 0; i < len(k); i++ {
+		key = append(key, k[i])
+		if k[i] == 0x00 {
+			i++
+		}
+	}
+	return string(key), rev
+}
+
+// PutRevision records value as the version of key at rev.
+func (b *Batch) PutRevision(key string, rev int64, value []byte) {
+	b.batch.Put(mvccKey(key, rev), append([]byte{versionPut}, value...))
+}
+
+// DeleteRevision records that key was deleted at rev.
+func (b *Batch) DeleteRevision(key string, rev int64) {
+	b.batch.Put(mvccKey(key, rev), []byte{versionDelete})
+}
+
+// GetAt returns the value of key as of rev and the revision that wrote it.
+func (r Reader) GetAt(key string, rev int64) (value []byte, modRev int64, ok bool) {
+	iter := r.snap.NewIterator(&util.Range{Start: mvccKey(key, 0), Limit: mvccKey(key, rev+1)}, nil)
+	defer iter.Release()
+	if !iter.Last() {
+		return nil, 0, false
+	}
+	v := iter.Value()
+	if v[0] == versionDelete {
+		return nil, 0, false
+	}
+	_, modRev = decodeMVCCKey(iter.Key())
+	return append([]byte{}, v[1:]...), modRev, true
+}
+
+// ScanAt is like Scan but visits the keys in [start, end) as of rev. fn also
+// receives the revision that wrote each value.
+func (r Reader) ScanAt(start, end string, rev int64, reverse bool, fn func(key string, value []byte, modRev int64) bool) {
+	rng := &util.Range{Start: escapeKey(start), Limit: escapeKey(end)}
+	if end == "" {
+		rng.Limit = util.BytesPrefix([]byte(mvccPrefix)).Limit
+	}
+	iter := r.snap.NewIterator(rng, nil)
+	defer iter.Release()
+	if reverse {
+		scanAtReverse(iter, rev, fn)
+	} else {
+		scanAtForward(iter, rev, fn)
+	}
+}
+
+// 正序遍历时同一个key的版本按revision升序出现，最后一个不大于rev的版本就是rev时的值
+func scanAtForward(iter iterator.Iterator, rev int64, fn func(key string, value []byte, modRev int64) bool) {
+	var cur string
+	var value []byte
+	var modRev int64
+	emit := func() bool {
+		if value == nil || value[0] == versionDelete {
+			return true
+		}
+		return fn(cur, value[1:], modRev)
+	}
+	for iter.Next() {
+		key, r := decodeMVCCKey(iter.Key())
+		if key != cur {
+			if !emit() {
+				return
+			}
+			cur, value = key, nil
+		}
+		if r <= rev {
+			value, modRev = append([]byte{}, iter.Value()...), r
+		}
+	}
+	emit()
+}
+
+// 逆序遍历时同一个key的版本按revision降序出现，第一个不大于rev的版本就是rev时的值
+func scanAtReverse(iter iterator.Iterator, rev int64, fn func(key string, value []byte, modRev int64) bool) {
+	done, started := "", false // 已经处理过的key
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		key, r := decodeMVCCKey(iter.Key())
+		if r > rev || started && key == done {
+			continue
+		}
+		done, started = key, true
+		if v := iter.Value(); v[0] == versionPut {
+			if !fn(key, append([]byte{}, v[1:]...), r) {
+				return
+			}
+		}
+	}
+}
+
+// CompactRevisions adds to b the deletion of every version that no read at
+// rev or later can see: for each key, all versions before the last one at or
+// below rev, and that one too if it is a deletion.
+func (p *Persister) CompactRevisions(b *Batch, rev int64) {
+	iter := p.db.NewIterator(util.BytesPrefix([]byte(mvccPrefix)), nil)
+	defer iter.Release()
+	var prev []byte // 当前key上一个不大于rev的版本
+	var prevKey string
+	prevDeleted := false
+	for iter.Next() {
+		key, r := decodeMVCCKey(iter.Key())
+		if key != prevKey {
+			if prev != nil && prevDeleted {
+				b.Delete(string(prev))
+			}
+			prevKey, prev = key, nil
+		}
+		if r > rev {
+			continue
+		}
+		if prev != nil {
+			b.Delete(string(prev))
+		}
+		prev = append([]byte{}, iter.Key()...)
+		prevDeleted = iter.Value()[0] == versionDelete
+	}
+	if prev != nil && prevDeleted {
+		b.Delete(string(prev))
+	}
+}
+
+// DropRevisions adds to b the deletion of every version of the keys for
+// which match returns true, for example when they move to another group.
+func (p *Persister) DropRevisions(b *Batch, match func(key string) bool) {
+	iter := p.db.NewIterator(util.BytesPrefix([]byte(mvccPrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		if key, _ := decodeMVCCKey(iter.Key()); match(key) {
+			b.Delete(string(iter.Key()))
+		}
+	}
+}
@@ -1,15 +1,56 @@
 package persister
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+// Raft的持久化状态和KV数据存放在同一个LevelDB中，使用保留的key前缀区分。
+// 状态机自己的元数据(例如客户端的去重表)使用ReservedPrefix下的其他key，
+// 和用户数据一起包含在快照中；只有Raft的持久化状态不属于快照
+const (
+	ReservedPrefix = "\x00"
+	raftPrefix     = ReservedPrefix + "raft/"
+	raftStateKey   = raftPrefix + "state"
+	raftLogPrefix  = raftPrefix + "log/" // 每条日志一个key，按index排序
+	appliedKey     = ReservedPrefix + "applied"
+)
+
+// 用户数据的key都不小于userStart，保留key不会被Scan访问到
+const userStart = "\x01"
+
+// 快照包含除Raft持久化状态之外的所有key
+func inSnapshot(key []byte) bool {
+	return !bytes.HasPrefix(key, []byte(raftPrefix))
+}
+
+func raftLogKey(index int64) string {
+	return fmt.Sprintf("%s%020d", raftLogPrefix, index)
+}
+
+type kvPair struct {
+	Key   []byte
+	Value []byte
+}
+
+// 删除一个key时LevelDB写入tombstone，空间要等compaction才回收，遍历(例如做快照)也要跳过这些tombstone。
+// 删除的key累计超过compactThreshold个之后在后台compact整个数据库
+const compactThreshold = 10000
+
 type Persister struct {
 	// path string
-	db *leveldb.DB
+	db         *leveldb.DB
+	tombstones int64 // 上一次compact之后删除的key数量，原子访问
+	compacting int32
 }
 
 func (p *Persister) Init(path string) {
@@ -22,10 +63,166 @@ func (p *Persister) Init(path string) {
 	}
 }
 
+func (p *Persister) Close() {
+	p.db.Close()
+}
+
 func (p *Persister) Put(key string, value string) {
 	p.db.Put([]byte(key), []byte(value), nil)
 }
 
+// Batch收集一组修改，由Write原子地写入
+type Batch struct {
+	batch   leveldb.Batch
+	deletes int
+}
+
+func (b *Batch) Put(key string, value []byte) {
+	b.batch.Put([]byte(key), value)
+}
+
+func (b *Batch) Delete(key string) {
+	b.batch.Delete([]byte(key))
+	b.deletes++
+}
+
+// SetApplied records in b that the state machine has applied every log
+// entry up to index, so the index is persisted together with the changes.
+func (b *Batch) SetApplied(index int64) {
+	b.Put(appliedKey, []byte(strconv.FormatInt(index, 10)))
+}
+
+// Applied returns the index recorded by the last SetApplied or
+// RestoreSnapshot, or 0 if there is none.
+func (p *Persister) Applied() int64 {
+	data, ok := p.Lookup(appliedKey)
+	if !ok {
+		return 0
+	}
+	index, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		log.Fatalln("decode applied index failed: ", err)
+	}
+	return index
+}
+
+// Write applies every change in b atomically. Like Put, the write is not
+// synced; it reaches the disk with the next SaveRaftState.
+func (p *Persister) Write(b *Batch) {
+	if err := p.db.Write(&b.batch, nil); err != nil {
+		log.Fatalln("Write failed: ", err)
+	}
+	p.addTombstones(b.deletes)
+}
+
+// Delete removes key. Like Put, the write is not synced.
+func (p *Persister) Delete(key string) {
+	if err := p.db.Delete([]byte(key), nil); err != nil {
+		log.Fatalln("Delete failed: ", err)
+	}
+	p.addTombstones(1)
+}
+
+func (p *Persister) addTombstones(n int) {
+	if n == 0 || atomic.AddInt64(&p.tombstones, int64(n)) < compactThreshold {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&p.compacting, 0, 1) {
+		return
+	}
+	atomic.StoreInt64(&p.tombstones, 0)
+	go func() {
+		defer atomic.StoreInt32(&p.compacting, 0)
+		if err := p.db.CompactRange(util.Range{}); err != nil && err != leveldb.ErrClosed {
+			log.Println("compact failed: ", err)
+		}
+	}()
+}
+
+// Lookup is like Get but reports a missing key with ok == false instead of
+// logging it.
+func (p *Persister) Lookup(key string) (value []byte, ok bool) {
+	value, err := p.db.Get([]byte(key), nil)
+	if err != nil {
+		if err != leveldb.ErrNotFound {
+			log.Println("Lookup failed: ", err)
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+// Reader reads from a point-in-time view of the database.
+type Reader struct {
+	snap *leveldb.Snapshot
+}
+
+// Lookup is like Persister.Lookup but reads from the view.
+func (r Reader) Lookup(key string) (value []byte, ok bool) {
+	value, err := r.snap.Get([]byte(key), nil)
+	if err != nil {
+		if err != leveldb.ErrNotFound {
+			log.Println("Lookup failed: ", err)
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+// Scan calls fn for every user key in [start, end) in key order, or in
+// reverse order if reverse is set, until fn returns false. An empty end
+// means no upper bound. Reserved keys are never visited.
+func (r Reader) Scan(start, end string, reverse bool, fn func(key string, value []byte) bool) {
+	rng := &util.Range{Start: []byte(start)}
+	if start < userStart {
+		rng.Start = []byte(userStart)
+	}
+	if end != "" {
+		if end <= string(rng.Start) {
+			return
+		}
+		rng.Limit = []byte(end)
+	}
+	iter := r.snap.NewIterator(rng, nil)
+	defer iter.Release()
+	ok := iter.First()
+	if reverse {
+		ok = iter.Last()
+	}
+	for ; ok; ok = nextOrPrev(iter, reverse) {
+		if !fn(string(iter.Key()), append([]byte{}, iter.Value()...)) {
+			return
+		}
+	}
+}
+
+func nextOrPrev(iter iterator.Iterator, reverse bool) bool {
+	if reverse {
+		return iter.Prev()
+	}
+	return iter.Next()
+}
+
+// View calls fn with a consistent view of the database, so that several
+// reads see the same batch of writes even while new ones are applied.
+func (p *Persister) View(fn func(r Reader)) {
+	snap, err := p.db.GetSnapshot()
+	if err != nil {
+		log.Fatalln("View failed: ", err)
+	}
+	defer snap.Release()
+	fn(Reader{snap: snap})
+}
+
+// ScanPrefix calls fn for every key starting with prefix in key order.
+func (p *Persister) ScanPrefix(prefix string, fn func(key string, value []byte)) {
+	iter := p.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		fn(string(iter.Key()), append([]byte{}, iter.Value()...))
+	}
+}
+
 func (p *Persister) Get(key string) []byte {
 	value, err := p.db.Get([]byte(key), nil)
 	if err != nil {
@@ -34,3 +231,107 @@ func (p *Persister) Get(key string) []byte {
 	}
 	return value
 }
+
+// SetRaftState records Raft's hard state in b.
+func (b *Batch) SetRaftState(data []byte) {
+	b.Put(raftStateKey, data)
+}
+
+// PutRaftEntry records in b the log entry at index, replacing any stored
+// entry with the same index.
+func (b *Batch) PutRaftEntry(index int64, data []byte) {
+	b.Put(raftLogKey(index), data)
+}
+
+// DeleteRaftEntry records in b that the log entry at index is removed.
+func (b *Batch) DeleteRaftEntry(index int64) {
+	b.Delete(raftLogKey(index))
+}
+
+// SaveRaftState writes a batch of changes to Raft's hard state and log
+// entries. The write is synced to disk so it survives a crash before the
+// caller replies to any RPC, and so do all earlier unsynced writes.
+func (p *Persister) SaveRaftState(b *Batch) {
+	if err := p.db.Write(&b.batch, &opt.WriteOptions{Sync: true}); err != nil {
+		log.Fatalln("SaveRaftState failed: ", err)
+	}
+	p.addTombstones(b.deletes)
+}
+
+// ReadRaftState returns the last saved Raft hard state, or nil on a fresh node.
+func (p *Persister) ReadRaftState() []byte {
+	data, err := p.db.Get([]byte(raftStateKey), nil)
+	if err != nil {
+		if err != leveldb.ErrNotFound {
+			log.Println("ReadRaftState failed: ", err)
+		}
+		return nil
+	}
+	return data
+}
+
+// ReadRaftLog calls fn for every stored log entry in index order.
+func (p *Persister) ReadRaftLog(fn func(index int64, data []byte)) {
+	p.ScanPrefix(raftLogPrefix, func(key string, value []byte) {
+		index, err := strconv.ParseInt(key[len(raftLogPrefix):], 10, 64)
+		if err != nil {
+			log.Fatalln("decode raft log key failed: ", err)
+		}
+		fn(index, value)
+	})
+}
+
+// Snapshot encodes every key/value pair of the state machine, including its
+// reserved metadata keys but not Raft's own state. The caller must make sure
+// nothing is applied concurrently so that the snapshot matches one log index.
+func (p *Persister) Snapshot() []byte {
+	snap, err := p.db.GetSnapshot()
+	if err != nil {
+		log.Fatalln("Snapshot failed: ", err)
+	}
+	defer snap.Release()
+	pairs := []kvPair{}
+	iter := snap.NewIterator(nil, nil)
+	for iter.Next() {
+		if !inSnapshot(iter.Key()) {
+			continue
+		}
+		pairs = append(pairs, kvPair{
+			Key:   append([]byte{}, iter.Key()...),
+			Value: append([]byte{}, iter.Value()...),
+		})
+	}
+	iter.Release()
+	data, _ := json.Marshal(pairs)
+	return data
+}
+
+// RestoreSnapshot replaces all state machine data with the snapshot taken at
+// log index in one batch, so a crash never leaves half of the old and half of
+// the new data, and records index as applied. Like Put, the write is not
+// synced; it reaches the disk with the next SaveRaftState.
+func (p *Persister) RestoreSnapshot(snapshot []byte, index int64) {
+	var pairs []kvPair
+	if err := json.Unmarshal(snapshot, &pairs); err != nil {
+		log.Fatalln("decode snapshot failed: ", err)
+	}
+	batch := new(leveldb.Batch)
+	deletes := 0
+	iter := p.db.NewIterator(nil, nil)
+	for iter.Next() {
+		if !inSnapshot(iter.Key()) {
+			continue
+		}
+		batch.Delete(append([]byte{}, iter.Key()...))
+		deletes++
+	}
+	iter.Release()
+	for _, kv := range pairs {
+		batch.Put(kv.Key, kv.Value)
+	}
+	batch.Put([]byte(appliedKey), []byte(strconv.FormatInt(index, 10)))
+	if err := p.db.Write(batch, nil); err != nil {
+		log.Fatalln("RestoreSnapshot failed: ", err)
+	}
+	p.addTombstones(deletes)
+}
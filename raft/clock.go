@@ -0,0 +1,112 @@
+package raft
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock是Raft使用的时间来源。选举超时、心跳、lease和各种等待都通过它计时，
+// 模拟测试可以用虚拟时钟替换系统时钟。
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+// Scheduler运行Raft的后台goroutine，并负责它们之间的唤醒和等待：选举循环、
+// 每个peer的复制循环、发送RPC的goroutine都由它启动，它们只通过Notify/Wait
+// 互相唤醒。模拟测试可以用它一次只运行一个goroutine，并且准确地知道什么时候
+// 所有goroutine都在等待，而不需要检查goroutine的调用栈。
+type Scheduler interface {
+	Go(f func())
+	// Notify唤醒在ch上等待的goroutine，没有goroutine等待时保留一次通知(ch的缓冲区为1)
+	Notify(ch chan bool)
+	// Wait等待chs中的一个收到通知或者经过d，返回收到通知的下标，超时返回-1。
+	// d<=0时不等待，只检查已有的通知
+	Wait(d time.Duration, chs ...chan bool) int
+}
+
+// 默认的Scheduler直接使用goroutine和channel，超时由clock计时
+type chanScheduler struct {
+	clock Clock
+}
+
+func (chanScheduler) Go(f func()) { go f() }
+
+func (chanScheduler) Notify(ch chan bool) {
+	select {
+	case <-ch: //if already set, consume it then resent to avoid block
+	default:
+	}
+	ch <- true
+}
+
+func (s chanScheduler) Wait(d time.Duration, chs ...chan bool) int {
+	// Raft最多同时等待两个channel，nil channel永远不会就绪
+	var ch0, ch1 chan bool
+	switch len(chs) {
+	case 2:
+		ch1 = chs[1]
+		fallthrough
+	case 1:
+		ch0 = chs[0]
+	case 0:
+	default:
+		panic("raft: too many channels to wait on")
+	}
+	if d <= 0 {
+		select {
+		case <-ch0:
+			return 0
+		case <-ch1:
+			return 1
+		default:
+			return -1
+		}
+	}
+	select {
+	case <-ch0:
+		return 0
+	case <-ch1:
+		return 1
+	case <-s.clock.After(d):
+		return -1
+	}
+}
+
+// Env describes everything a Raft instance needs from the outside world
+// besides its storage. Tests replace the network, the clock and the random
+// source to run a cluster deterministically.
+type Env struct {
+	Transport Transport
+	Clock     Clock      // nil表示使用系统时钟
+	Scheduler Scheduler  // nil表示直接使用goroutine和channel
+	Rand      *rand.Rand // 选举超时的随机数来源，nil表示使用全局随机数
+	// 发送AppendEntries之前不再随机等待0~25ms，延迟完全由Transport模拟
+	NoSendJitter bool
+}
+
+// 唤醒等待ch的goroutine，例如重置选举超时、通知复制循环发送日志
+func (rf *Raft) send(ch chan bool) {
+	rf.sched.Notify(ch)
+}
+
+// Raft的后台goroutine里的等待都通过Scheduler，不直接使用clock
+func (rf *Raft) sleep(d time.Duration) {
+	rf.sched.Wait(d)
+}
+
+func (rf *Raft) randIntn(n int) int {
+	if rf.rand == nil {
+		return rand.Intn(n)
+	}
+	rf.randMu.Lock()
+	defer rf.randMu.Unlock()
+	return rf.rand.Intn(n)
+}
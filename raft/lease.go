@@ -0,0 +1,69 @@
+package raft
+
+import (
+	"sort"
+	"time"
+)
+
+// Leader lease：follower在收到leader心跳后的minElectionTimeout内不会给其他节点投票
+// (见RequestVote和PreVote)，所以leader在多数成员响应的心跳发出之后的
+// minElectionTimeout-clockDrift内不会被取代，可以直接读本地数据。
+
+// 记录第slot个成员对sent时刻发出的心跳的响应，调用者需要持有rf.mu
+func (rf *Raft) recordLeaseAck(slot int, sent time.Time) {
+	if rf.state != Leader || rf.leaseAcks == nil {
+		return
+	}
+	if sent.After(rf.leaseAcks[int32(slot)]) {
+		rf.leaseAcks[int32(slot)] = sent
+	}
+}
+
+// lease的到期时间，调用者需要持有rf.mu
+func (rf *Raft) leaseExpiry() time.Time {
+	if rf.state != Leader || rf.leaseAcks == nil {
+		return time.Time{}
+	}
+	now := rf.clock.Now()
+	var acks []time.Time
+	for i := range rf.members {
+		if !rf.isVoter(i) {
+			continue
+		}
+		if int32(i) == rf.me {
+			acks = append(acks, now)
+		} else {
+			acks = append(acks, rf.leaseAcks[int32(i)])
+		}
+	}
+	if len(acks) == 0 {
+		return time.Time{}
+	}
+	// 多数成员响应过的最晚的心跳发送时间
+	sort.Slice(acks, func(i, j int) bool { return acks[i].After(acks[j]) })
+	return acks[len(acks)/2].Add(minElectionTimeout - rf.clockDrift)
+}
+
+// LeaseRead serves a read without a heartbeat round while the leader
+// holds a valid lease. Once the lease has expired it falls back to
+// ReadIndex. It returns the read index and false if this server cannot
+// serve the read.
+func (rf *Raft) LeaseRead() (int32, bool) {
+	rf.mu.Lock()
+	if rf.state != Leader {
+		rf.mu.Unlock()
+		return -1, false
+	}
+	term := rf.currentTerm
+	valid := rf.clock.Now().Before(rf.leaseExpiry()) && rf.logAt(rf.commitIndex).Term == term
+	readIndex := rf.commitIndex
+	rf.mu.Unlock()
+
+	if !valid {
+		return rf.ReadIndex()
+	}
+	if !rf.waitApplied(readIndex) {
+		return -1, false
+	}
+	return readIndex, true
+}
@@ -0,0 +1,402 @@
+package raft
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"hckvstore/util"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// 集群配置随日志复制，使用single-server changes：每次只增加或移除一个成员，
+// 配置日志一旦追加到本地日志就立即生效(不需要等待提交)。
+// rf.members中每个成员的下标就是它的id，下标永远不会复用，被移除的成员位置为空字符串，
+// 这样所有节点根据同样的日志计算出的id是一致的。
+// 每个成员有voter和learner两种角色：learner接收日志复制，但不参与选举和commitIndex的多数派计算，
+// 可以作为只读副本或者热备，追上日志之后再提升为voter。
+const (
+	opAddServer      = "AddServer"
+	opRemoveServer   = "RemoveServer"
+	opAddLearner     = "AddLearner"
+	opPromoteLearner = "PromoteLearner"
+)
+
+var (
+	ErrNotLeader           = errors.New("not leader")
+	ErrConfigChangePending = errors.New("another membership change is in progress")
+	ErrNotMember           = errors.New("address is not a member")
+	ErrNotLearner          = errors.New("address is not a learner")
+	ErrNotVoter            = errors.New("address is not a voting member")
+	ErrCatchUpTimeout      = errors.New("new server failed to catch up with the leader")
+	ErrCommitTimeout       = errors.New("membership change was not committed in time")
+)
+
+const (
+	// 新成员追赶leader日志的最大轮数，每一轮追赶到本轮开始时leader的最后一条日志
+	maxCatchUpRounds = 10
+	// 某一轮追赶在一个最小选举超时内完成，就认为新成员已经追上
+	catchUpRoundTime = minElectionTimeout
+	commitTimeout    = 5 * time.Second
+)
+
+func isConfigChange(l Log) bool {
+	return l.Type == RPC.EntryType_EntryConfChange
+}
+
+func decodeConfChange(l Log) *RPC.ConfChange {
+	cc := &RPC.ConfChange{}
+	if err := proto.Unmarshal(l.Data, cc); err != nil {
+		log.Fatalf("decode config change failed: %v", err)
+	}
+	return cc
+}
+
+// 在配置上执行一条配置日志，learners会被原地修改
+func applyConfigChange(members []string, learners map[string]bool, cc *RPC.ConfChange) []string {
+	switch cc.Type {
+	case opAddServer, opAddLearner:
+		for _, m := range members {
+			if m == cc.Address {
+				return members
+			}
+		}
+		members = append(members, cc.Address)
+		if cc.Type == opAddLearner {
+			learners[cc.Address] = true
+		}
+	case opRemoveServer:
+		for i, m := range members {
+			if m == cc.Address {
+				members[i] = ""
+			}
+		}
+		delete(learners, cc.Address)
+	case opPromoteLearner:
+		delete(learners, cc.Address)
+	}
+	return members
+}
+
+// index处(包含)的集群配置和其中的learner，index不能小于lastIncludedIndex
+func (rf *Raft) configAt(index int32) ([]string, map[string]bool) {
+	members := make([]string, len(rf.baseMembers))
+	copy(members, rf.baseMembers)
+	learners := make(map[string]bool)
+	for _, l := range rf.baseLearners {
+		learners[l] = true
+	}
+	for i := rf.lastIncludedIndex + 1; i <= index; i++ {
+		if l := rf.logAt(i); isConfigChange(l) {
+			members = applyConfigChange(members, learners, decodeConfChange(l))
+		}
+	}
+	return members, learners
+}
+
+// 根据快照中的配置和日志中所有的配置日志重新计算当前配置，
+// 日志被截断或者追加了配置日志之后调用
+func (rf *Raft) reloadConfig() {
+	rf.setMembers(rf.configAt(rf.getLastLogIdx()))
+}
+
+func (rf *Raft) setMembers(members []string, learners map[string]bool) {
+	rf.members = members
+	rf.learners = learners
+	rf.me = NULL
+	inConfig := make(map[string]bool)
+	for i, m := range members {
+		if m == rf.address {
+			rf.me = int32(i)
+		}
+		inConfig[m] = true
+	}
+	// 关闭已经被移出集群的成员的连接
+	rf.transport.Retain(func(address string) bool {
+		return inConfig[address] || address == rf.addingServer
+	})
+}
+
+// 持久化和InstallSnapshot使用的learner列表
+func learnerList(learners map[string]bool) []string {
+	list := make([]string, 0, len(learners))
+	for l := range learners {
+		list = append(list, l)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// 第i个成员是否参与投票(没有被移除并且不是learner)
+func (rf *Raft) isVoter(i int) bool {
+	return i >= 0 && i < len(rf.members) && rf.members[i] != "" && !rf.learners[rf.members[i]]
+}
+
+// 当前配置中参与投票的成员数量(不包括learner和已经移除的成员)
+func (rf *Raft) numVoters() int {
+	n := 0
+	for i := range rf.members {
+		if rf.isVoter(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// leader复制日志的目标地址，i可能是正在追赶日志、还没有加入配置的新成员
+func (rf *Raft) peerAddress(i int) string {
+	if i < len(rf.members) {
+		return rf.members[i]
+	}
+	return rf.addingServer
+}
+
+// 是否有还没提交的配置日志
+func (rf *Raft) configChangePending() bool {
+	if rf.addingServer != "" {
+		return true
+	}
+	for i := rf.commitIndex + 1; i <= rf.getLastLogIdx(); i++ {
+		if isConfigChange(rf.logAt(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 配置变更前leader必须已经提交过当前term的日志，
+// 否则可能和上一个term未提交的配置变更冲突
+func (rf *Raft) checkConfigChange() error {
+	if rf.state != Leader {
+		return ErrNotLeader
+	}
+	if rf.transferTarget != "" {
+		return ErrTransferInProgress
+	}
+	if rf.configChangePending() || rf.logAt(rf.commitIndex).Term != rf.currentTerm {
+		return ErrConfigChangePending
+	}
+	return nil
+}
+
+// AddServer adds address to the cluster. The new server first catches up
+// with the leader's log as a non-voting peer and only then is the
+// configuration entry appended, so it never counts toward quorum while
+// lagging behind. It blocks until the change is committed.
+func (rf *Raft) AddServer(address string) error {
+	rf.mu.Lock()
+	if err := rf.checkConfigChange(); err != nil {
+		rf.mu.Unlock()
+		return err
+	}
+	for _, m := range rf.members {
+		if m == address {
+			rf.mu.Unlock()
+			return nil
+		}
+	}
+	// 新成员的位置固定为len(rf.members)，nextIndex从0开始会先发送快照
+	slot := len(rf.members)
+	rf.addingServer = address
+	rf.nextIndex = append(rf.nextIndex[:slot], 0)
+	rf.matchIndex = append(rf.matchIndex[:slot], 0)
+	term := rf.currentTerm
+	rf.mu.Unlock()
+	util.DPrintf("[%v] (term %d) start catching up new server %v", rf.address, term, address)
+
+	caughtUp := rf.catchUp(slot, term)
+
+	rf.mu.Lock()
+	rf.addingServer = ""
+	if !caughtUp || rf.state != Leader || rf.currentTerm != term {
+		if rf.state == Leader && rf.currentTerm == term {
+			rf.nextIndex = rf.nextIndex[:slot]
+			rf.matchIndex = rf.matchIndex[:slot]
+		}
+		rf.mu.Unlock()
+		return ErrCatchUpTimeout
+	}
+	index := rf.appendConfigChange(&RPC.ConfChange{Type: opAddServer, Address: address})
+	rf.mu.Unlock()
+	rf.startAppendLog()
+	return rf.waitCommitted(index, term, commitTimeout)
+}
+
+// 分轮等待第slot个peer追上leader的日志
+func (rf *Raft) catchUp(slot int, term int32) bool {
+	for round := 0; round < maxCatchUpRounds; round++ {
+		rf.mu.Lock()
+		target := rf.getLastLogIdx()
+		rf.mu.Unlock()
+		start := rf.clock.Now()
+		if !rf.waitMatch(slot, target, term, commitTimeout) {
+			return false
+		}
+		if rf.clock.Now().Sub(start) < catchUpRoundTime {
+			return true
+		}
+	}
+	return false
+}
+
+// AddLearner adds address to the cluster as a non-voting learner. A learner
+// receives the replicated log but takes no part in elections or in the
+// commit quorum, so it is added without waiting for it to catch up.
+// It blocks until the change is committed.
+func (rf *Raft) AddLearner(address string) error {
+	rf.mu.Lock()
+	if err := rf.checkConfigChange(); err != nil {
+		rf.mu.Unlock()
+		return err
+	}
+	for _, m := range rf.members {
+		if m == address {
+			rf.mu.Unlock()
+			return nil
+		}
+	}
+	term := rf.currentTerm
+	index := rf.appendConfigChange(&RPC.ConfChange{Type: opAddLearner, Address: address})
+	rf.mu.Unlock()
+	rf.startAppendLog()
+	return rf.waitCommitted(index, term, commitTimeout)
+}
+
+// PromoteLearner turns the learner address into a voter once its
+// matchIndex shows that it has caught up with the leader's log.
+// It blocks until the change is committed.
+func (rf *Raft) PromoteLearner(address string) error {
+	rf.mu.Lock()
+	if err := rf.checkConfigChange(); err != nil {
+		rf.mu.Unlock()
+		return err
+	}
+	slot := -1
+	for i, m := range rf.members {
+		if m == address {
+			slot = i
+		}
+	}
+	if slot < 0 {
+		rf.mu.Unlock()
+		return ErrNotMember
+	}
+	if !rf.learners[address] {
+		rf.mu.Unlock()
+		return ErrNotLearner
+	}
+	term := rf.currentTerm
+	rf.mu.Unlock()
+
+	if !rf.catchUp(slot, term) {
+		return ErrCatchUpTimeout
+	}
+	rf.mu.Lock()
+	// 追赶期间可能已经有其他配置变更
+	if err := rf.checkConfigChange(); err != nil || rf.currentTerm != term {
+		rf.mu.Unlock()
+		if err == nil {
+			err = ErrNotLeader
+		}
+		return err
+	}
+	if !rf.learners[address] {
+		rf.mu.Unlock()
+		return ErrNotLearner
+	}
+	index := rf.appendConfigChange(&RPC.ConfChange{Type: opPromoteLearner, Address: address})
+	rf.mu.Unlock()
+	rf.startAppendLog()
+	return rf.waitCommitted(index, term, commitTimeout)
+}
+
+// RemoveServer removes address from the cluster and blocks until the
+// change is committed. A leader that removes itself steps down afterwards.
+func (rf *Raft) RemoveServer(address string) error {
+	rf.mu.Lock()
+	if err := rf.checkConfigChange(); err != nil {
+		rf.mu.Unlock()
+		return err
+	}
+	found := false
+	for _, m := range rf.members {
+		if m == address {
+			found = true
+		}
+	}
+	if !found {
+		rf.mu.Unlock()
+		return ErrNotMember
+	}
+	term := rf.currentTerm
+	index := rf.appendConfigChange(&RPC.ConfChange{Type: opRemoveServer, Address: address})
+	rf.mu.Unlock()
+	rf.startAppendLog()
+	err := rf.waitCommitted(index, term, commitTimeout)
+
+	rf.mu.Lock()
+	if err == nil && rf.me == NULL && rf.state == Leader {
+		// leader把自己移除之后不再参与集群
+		util.DPrintf("[%v] (term %d) removed from the cluster, step down", rf.address, rf.currentTerm)
+		rf.state = Follower
+		rf.leaseAcks = nil
+	}
+	rf.mu.Unlock()
+	return err
+}
+
+// leader追加一条配置日志并立即使用新配置，调用者需要持有rf.mu
+func (rf *Raft) appendConfigChange(cc *RPC.ConfChange) int32 {
+	index := rf.getLastLogIdx() + 1
+	data, _ := proto.Marshal(cc)
+	rf.log = append(rf.log, Log{Term: rf.currentTerm, Type: RPC.EntryType_EntryConfChange, Data: data})
+	rf.setMembers(applyConfigChange(rf.members, rf.learners, cc), rf.learners)
+	if len(rf.nextIndex) < len(rf.members) {
+		// AddServer时已经为新成员分配过位置，这里只是防御
+		rf.nextIndex = append(rf.nextIndex, 0)
+		rf.matchIndex = append(rf.matchIndex, 0)
+	}
+	rf.persist()
+	util.DPrintf("[%v] (term %d) append config change %v %v at index %v, members: %v", rf.address, rf.currentTerm, cc.Type, cc.Address, index, rf.members)
+	return index
+}
+
+// 等待第slot个peer的matchIndex达到target
+func (rf *Raft) waitMatch(slot int, target int32, term int32, timeout time.Duration) bool {
+	for start := rf.clock.Now(); rf.clock.Now().Sub(start) < timeout; {
+		rf.mu.Lock()
+		if rf.state != Leader || rf.currentTerm != term || rf.killed() {
+			rf.mu.Unlock()
+			return false
+		}
+		if rf.matchIndex[slot] >= target {
+			rf.mu.Unlock()
+			return true
+		}
+		rf.mu.Unlock()
+		rf.clock.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+// 等待term任期内写入的index日志被提交
+func (rf *Raft) waitCommitted(index int32, term int32, timeout time.Duration) error {
+	for start := rf.clock.Now(); rf.clock.Now().Sub(start) < timeout; {
+		rf.mu.Lock()
+		if rf.currentTerm != term || rf.killed() {
+			rf.mu.Unlock()
+			return ErrNotLeader
+		}
+		if rf.commitIndex >= index {
+			rf.mu.Unlock()
+			return nil
+		}
+		rf.mu.Unlock()
+		rf.clock.Sleep(10 * time.Millisecond)
+	}
+	return ErrCommitTimeout
+}
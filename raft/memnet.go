@@ -0,0 +1,266 @@
+package raft
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Network是进程内的模拟网络，用来在go test里运行整个集群而不需要监听TCP端口。
+// 每个请求在独立的goroutine里投递到目标节点的handler，可以配置延迟、丢包、
+// 重复和乱序，也可以把节点划分到互不连通的分区。
+// 请求和响应在投递前都会被复制，和经过gRPC序列化一样，收发双方不会共享消息。
+
+var errUnreachable = errors.New("memnet: destination unreachable")
+
+// NetworkConfig describes how unreliable the in-memory network is. The zero
+// value delivers every message immediately and exactly once.
+type NetworkConfig struct {
+	MinDelay      time.Duration // 每个请求和响应的延迟在[MinDelay, MaxDelay]之间
+	MaxDelay      time.Duration
+	DropRate      float64 // 请求或者响应丢失的概率
+	DuplicateRate float64 // 请求被重复投递的概率，重复投递的响应被丢弃
+	ReorderRate   float64 // 请求额外延迟几个MaxDelay，被之后发出的请求超过的概率
+}
+
+type Network struct {
+	mu        sync.Mutex
+	rand      *rand.Rand
+	config    NetworkConfig
+	handlers  map[string]RPC.RAFTServer
+	partition map[string]int // 地址所在的分区，nil表示网络没有分区
+}
+
+// NewNetwork returns a fully connected, reliable network. seed drives the
+// random delays and losses so that a failing run can be repeated.
+func NewNetwork(seed int64) *Network {
+	return &Network{
+		rand:     rand.New(rand.NewSource(seed)),
+		handlers: make(map[string]RPC.RAFTServer),
+	}
+}
+
+func (n *Network) Configure(cfg NetworkConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.config = cfg
+}
+
+// Partition splits the network into the given groups. Nodes in different
+// groups cannot reach each other and nodes not listed in any group are
+// isolated from everyone.
+func (n *Network) Partition(groups ...[]string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partition = make(map[string]int)
+	for i, group := range groups {
+		for _, address := range group {
+			n.partition[address] = i
+		}
+	}
+}
+
+// Heal removes any partition.
+func (n *Network) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partition = nil
+}
+
+// Transport returns the Transport of the node listening on address.
+func (n *Network) Transport(address string) Transport {
+	return &memTransport{net: n, address: address, done: make(chan struct{})}
+}
+
+// 调用者需要持有n.mu
+func (n *Network) reachable(from, to string) bool {
+	if n.partition == nil {
+		return true
+	}
+	g1, ok1 := n.partition[from]
+	g2, ok2 := n.partition[to]
+	return ok1 && ok2 && g1 == g2
+}
+
+// 调用者需要持有n.mu
+func (n *Network) delay() time.Duration {
+	d := n.config.MinDelay
+	if n.config.MaxDelay > d {
+		d += time.Duration(n.rand.Int63n(int64(n.config.MaxDelay - d)))
+	}
+	if n.rand.Float64() < n.config.ReorderRate {
+		d += time.Duration(1+n.rand.Intn(3)) * n.config.MaxDelay
+	}
+	return d
+}
+
+// 返回from此时能否把消息交给to，以及to的handler
+func (n *Network) handler(from, to string) (RPC.RAFTServer, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	h, ok := n.handlers[to]
+	if !ok || !n.reachable(from, to) {
+		return nil, false
+	}
+	if _, ok := n.handlers[from]; !ok {
+		// 发送者已经Close
+		return nil, false
+	}
+	return h, true
+}
+
+type memTransport struct {
+	net     *Network
+	address string
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (t *memTransport) Serve(address string, handler RPC.RAFTServer) {
+	t.net.mu.Lock()
+	select {
+	case <-t.done:
+		t.net.mu.Unlock()
+		return
+	default:
+	}
+	t.net.handlers[address] = handler
+	t.net.mu.Unlock()
+	<-t.done
+}
+
+func (t *memTransport) Client(address string) (RPC.RAFTClient, error) {
+	select {
+	case <-t.done:
+		return nil, errPeersClosed
+	default:
+	}
+	return &memClient{t: t, to: address}, nil
+}
+
+// 模拟网络没有需要释放的连接
+func (t *memTransport) Retain(keep func(address string) bool) {}
+
+func (t *memTransport) Close() {
+	t.once.Do(func() {
+		t.net.mu.Lock()
+		delete(t.net.handlers, t.address)
+		close(t.done)
+		t.net.mu.Unlock()
+	})
+}
+
+// 把req投递给to，call在目标节点上处理请求
+func (t *memTransport) deliver(ctx context.Context, to string, req proto.Message,
+	call func(h RPC.RAFTServer, req proto.Message) (proto.Message, error)) (proto.Message, error) {
+	n := t.net
+	n.mu.Lock()
+	reqDelay, replyDelay, dupDelay := n.delay(), n.delay(), n.delay()
+	dropReq := n.rand.Float64() < n.config.DropRate
+	dropReply := n.rand.Float64() < n.config.DropRate
+	dup := n.rand.Float64() < n.config.DuplicateRate
+	n.mu.Unlock()
+
+	if dup {
+		go func() {
+			time.Sleep(dupDelay)
+			if h, ok := n.handler(t.address, to); ok {
+				call(h, proto.Clone(req))
+			}
+		}()
+	}
+	if !sleepCtx(ctx, reqDelay) {
+		return nil, ctx.Err()
+	}
+	h, ok := n.handler(t.address, to)
+	if !ok || dropReq {
+		return nil, errUnreachable
+	}
+	reply, err := call(h, proto.Clone(req))
+	if err != nil {
+		return nil, err
+	}
+	if !sleepCtx(ctx, replyDelay) {
+		return nil, ctx.Err()
+	}
+	if _, ok := n.handler(to, t.address); !ok || dropReply {
+		return nil, errUnreachable
+	}
+	return proto.Clone(reply), nil
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+type memClient struct {
+	t  *memTransport
+	to string
+}
+
+func (c *memClient) RequestVote(ctx context.Context, in *RPC.RequestVoteArgs, opts ...grpc.CallOption) (*RPC.RequestVoteReply, error) {
+	reply, err := c.t.deliver(ctx, c.to, in, func(h RPC.RAFTServer, req proto.Message) (proto.Message, error) {
+		return h.RequestVote(ctx, req.(*RPC.RequestVoteArgs))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.RequestVoteReply), nil
+}
+
+func (c *memClient) AppendEntries(ctx context.Context, in *RPC.AppendEntriesArgs, opts ...grpc.CallOption) (*RPC.AppendEntriesReply, error) {
+	reply, err := c.t.deliver(ctx, c.to, in, func(h RPC.RAFTServer, req proto.Message) (proto.Message, error) {
+		return h.AppendEntries(ctx, req.(*RPC.AppendEntriesArgs))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.AppendEntriesReply), nil
+}
+
+func (c *memClient) InstallSnapshot(ctx context.Context, in *RPC.InstallSnapshotArgs, opts ...grpc.CallOption) (*RPC.InstallSnapshotReply, error) {
+	reply, err := c.t.deliver(ctx, c.to, in, func(h RPC.RAFTServer, req proto.Message) (proto.Message, error) {
+		return h.InstallSnapshot(ctx, req.(*RPC.InstallSnapshotArgs))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.InstallSnapshotReply), nil
+}
+
+func (c *memClient) PreVote(ctx context.Context, in *RPC.RequestVoteArgs, opts ...grpc.CallOption) (*RPC.RequestVoteReply, error) {
+	reply, err := c.t.deliver(ctx, c.to, in, func(h RPC.RAFTServer, req proto.Message) (proto.Message, error) {
+		return h.PreVote(ctx, req.(*RPC.RequestVoteArgs))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.RequestVoteReply), nil
+}
+
+func (c *memClient) TimeoutNow(ctx context.Context, in *RPC.TimeoutNowArgs, opts ...grpc.CallOption) (*RPC.TimeoutNowReply, error) {
+	reply, err := c.t.deliver(ctx, c.to, in, func(h RPC.RAFTServer, req proto.Message) (proto.Message, error) {
+		return h.TimeoutNow(ctx, req.(*RPC.TimeoutNowArgs))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.TimeoutNowReply), nil
+}
@@ -0,0 +1,75 @@
+package raft
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+)
+
+// 每个peer保持一个长连接，所有RPC复用这个连接。
+// 连接断开后由grpc.ClientConn在后台按指数退避重连，连接不可用时RPC立即返回错误，
+// 不会阻塞调用者，也不会因为某个peer下线而退出进程。
+type peerConns struct {
+	mu     sync.Mutex
+	conns  map[string]*grpc.ClientConn
+	closed bool // Kill之后不再建立新连接
+}
+
+var errPeersClosed = errors.New("peer connections closed")
+
+// 重连的最大间隔不超过心跳间隔，否则重启的follower在leader重连之前就会选举超时
+var peerConnectParams = grpc.ConnectParams{
+	Backoff: backoff.Config{
+		BaseDelay:  20 * time.Millisecond,
+		Multiplier: 1.6,
+		Jitter:     0.2,
+		MaxDelay:   heartbeatTime,
+	},
+	MinConnectTimeout: minElectionTimeout,
+}
+
+// 返回address对应的client，第一次使用时建立连接
+func (p *peerConns) client(address string) (RPC.RAFTClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, errPeersClosed
+	}
+	if conn, ok := p.conns[address]; ok {
+		return RPC.NewRAFTClient(conn), nil
+	}
+	// 不使用WithBlock，连接在后台建立
+	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithConnectParams(peerConnectParams))
+	if err != nil {
+		return nil, err
+	}
+	if p.conns == nil {
+		p.conns = make(map[string]*grpc.ClientConn)
+	}
+	p.conns[address] = conn
+	return RPC.NewRAFTClient(conn), nil
+}
+
+// 关闭不再需要的连接，例如已经被移出集群的成员
+func (p *peerConns) retain(keep func(address string) bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for address, conn := range p.conns {
+		if !keep(address) {
+			conn.Close()
+			delete(p.conns, address)
+		}
+	}
+}
+
+func (p *peerConns) closeAll() {
+	p.retain(func(string) bool { return false })
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+}
@@ -0,0 +1,114 @@
+package raft
+
+import (
+	"sync/atomic"
+	"time"
+
+	"hckvstore/util"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"golang.org/x/net/context"
+)
+
+// PreVote(Raft论文§9.6)：选举超时之后先询问其他成员是否会给自己投票，
+// 得到多数同意之后才增加term开始真正的选举。被分区隔离的节点不会不断增加term，
+// 分区恢复之后也不会用更大的term迫使正常工作的leader下台。
+
+// 调用者需要持有rf.mu
+func (rf *Raft) bePreCandidate() {
+	util.DPrintf("[%v] (term %d, state %d) is becoming PreCandidate!", rf.address, rf.currentTerm, rf.state)
+	rf.state = PreCandidate
+	rf.sched.Go(rf.startPreVote)
+}
+
+func (rf *Raft) startPreVote() {
+	rf.mu.Lock()
+	if rf.state != PreCandidate {
+		rf.mu.Unlock()
+		return
+	}
+	term := rf.currentTerm
+	args := RPC.RequestVoteArgs{
+		Term:         term + 1, // 选举成功后将会使用的term
+		CandidateId:  rf.me,
+		LastLogIndex: rf.getLastLogIdx(),
+		LastLogTerm:  rf.getLastLogTerm(),
+	}
+	var members []string
+	for i, m := range rf.members {
+		if rf.isVoter(i) {
+			members = append(members, m)
+		}
+	}
+	quorum := int32(rf.numVoters()/2 + 1)
+	var votes int32 = 1
+	if votes >= quorum {
+		rf.beCandidate(false)
+		rf.mu.Unlock()
+		return
+	}
+	rf.mu.Unlock()
+
+	for i := 0; i < len(members); i++ {
+		if rf.address == members[i] || members[i] == "" {
+			continue
+		}
+		address := members[i]
+		rf.sched.Go(func() {
+			ret, reply := rf.sendPreVote(address, &args)
+			if !ret {
+				return
+			}
+			rf.mu.Lock()
+			defer rf.mu.Unlock()
+			if reply.Term > rf.currentTerm {
+				rf.beFollower(reply.Term)
+				return
+			}
+			if rf.state != PreCandidate || rf.currentTerm != term {
+				return
+			}
+			if reply.VoteGranted && atomic.AddInt32(&votes, 1) == quorum {
+				util.DPrintf("[%v] (term %d, state %d) got pre-votes from majority", rf.address, rf.currentTerm, rf.state)
+				rf.beCandidate(false)
+				rf.send(rf.voteCh) //reset election timer for the real election
+			}
+		})
+	}
+}
+
+// PreVote RPC handler. 不修改自己的term和votedFor
+func (rf *Raft) PreVote(ctx context.Context, args *RPC.RequestVoteArgs) (*RPC.RequestVoteReply, error) {
+	if !rf.connected() {
+		return nil, errDisconnected
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	reply := &RPC.RequestVoteReply{Term: rf.currentTerm}
+	if rf.state == Leader || rf.clock.Now().Sub(rf.lastHeartbeat) < minElectionTimeout {
+		// 当前leader还在正常工作
+		return reply, nil
+	}
+	reply.VoteGranted = args.Term > rf.currentTerm && rf.isLogUpToDate(args.LastLogIndex, args.LastLogTerm)
+	return reply, nil
+}
+
+func (rf *Raft) sendPreVote(address string, args *RPC.RequestVoteArgs) (bool, *RPC.RequestVoteReply) {
+	if !rf.connected() {
+		return false, nil
+	}
+	client, err := rf.transport.Client(address)
+	if err != nil {
+		util.DPrintf("sendPreVote did not connect: %v %v", err, address)
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err := client.PreVote(ctx, args)
+	if err != nil {
+		return false, reply
+	}
+	return true, reply
+}
@@ -0,0 +1,103 @@
+package raft
+
+import (
+	"errors"
+
+	"hckvstore/util"
+
+	"golang.org/x/net/context"
+)
+
+// Propose提交的命令对应一个Proposal，记录日志的(Index, Term)。
+// 这个位置apply的日志term相同，说明就是这条命令；term不同说明这条日志被新leader的日志覆盖，
+// 命令一定没有执行。leader下台之后日志是否会被提交只能等待之后的apply，调用者通过ctx限制等待时间。
+
+var (
+	ErrProposalDropped = errors.New("proposal was overwritten by an entry from another term")
+	ErrProposalUnknown = errors.New("proposal outcome is unknown")
+)
+
+type Proposal struct {
+	Index  int32
+	Term   int32
+	done   chan struct{}
+	err    error
+	result interface{}
+}
+
+// Wait blocks until the proposed entry has been applied (nil), is known to
+// be lost (ErrProposalDropped), or ctx is done. Any other error means the
+// command may or may not be applied later.
+func (p *Proposal) Wait(ctx context.Context) error {
+	select {
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Result returns what the state machine's Apply returned for the entry. It
+// is only meaningful after Wait returned nil.
+func (p *Proposal) Result() interface{} {
+	return p.result
+}
+
+// Propose appends command to the leader's log like Start and returns a
+// handle that resolves when that exact entry is applied.
+func (rf *Raft) Propose(command interface{}) (*Proposal, error) {
+	index, term, p := rf.start(command, true)
+	if index < 0 {
+		return nil, ErrNotLeader
+	}
+	rf.startAppendLog()
+	util.DPrintf("[%v] (term %d) proposal at index %d", rf.address, term, index)
+	return p, nil
+}
+
+// 调用者需要持有rf.mu
+func (rf *Raft) resolveProposal(index int32, term int32, result interface{}) {
+	p, ok := rf.proposals[index]
+	if !ok {
+		return
+	}
+	delete(rf.proposals, index)
+	if p.Term == term {
+		p.result = result
+		p.resolve(nil)
+	} else {
+		p.resolve(ErrProposalDropped)
+	}
+}
+
+// 安装快照之前调用，快照覆盖了index及之前的所有等待中的提案。
+// 本地日志在index处和快照的term相同时，之前的日志都和已提交的日志一致，可以确定提案是否被覆盖；
+// 但快照中的命令没有经过本地的Apply，即使已经执行也拿不到结果，同样返回结果未知，
+// 调用者可以重试，由状态机的去重表返回第一次执行的结果。调用者需要持有rf.mu
+func (rf *Raft) resolveProposalsInSnapshot(index int32, term int32) {
+	matched := index >= rf.lastIncludedIndex && index <= rf.getLastLogIdx() && rf.logAt(index).Term == term
+	for i := range rf.proposals {
+		if i > index {
+			continue
+		}
+		if matched && i > rf.lastIncludedIndex && rf.logAt(i).Term != rf.proposals[i].Term {
+			rf.resolveProposal(i, rf.logAt(i).Term, nil)
+			continue
+		}
+		rf.proposals[i].resolve(ErrProposalUnknown)
+		delete(rf.proposals, i)
+	}
+}
+
+// 节点被Kill之后不再apply，等待中的提案结果未知。调用者需要持有rf.mu
+func (rf *Raft) abortProposals() {
+	for i, p := range rf.proposals {
+		p.resolve(ErrProposalUnknown)
+		delete(rf.proposals, i)
+	}
+}
+
+func (p *Proposal) resolve(err error) {
+	p.err = err
+	close(p.done)
+}
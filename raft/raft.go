@@ -2,10 +2,10 @@ package raft
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
-	"net"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -13,34 +13,40 @@ import (
 
 	"hckvstore/util"
 
-	config "hckvstore/config"
 	Per "hckvstore/persister"
 	RPC "hckvstore/rpc/raftrpc"
 
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
 )
 
 type State int
 
 const (
-	Follower  State = iota // value --> 0
-	Candidate              // value --> 1
-	Leader                 // value --> 2
+	Follower     State = iota // value --> 0
+	Candidate                 // value --> 1
+	Leader                    // value --> 2
+	PreCandidate              // value --> 3, PreVote阶段，还没有增加term
 )
 const NULL int32 = -1
 
 type Log struct {
-	Term int32 //  "term when entry was received by leader"
-	// Debug 原来是interface{}类型，但是因为json序列化和反序列化时Command类型转化有问题
-	Command config.Op //"command for state machine,"
+	Term int32         //  "term when entry was received by leader"
+	Type RPC.EntryType // 日志的类型，决定Data的格式
+	Data []byte        //"command for state machine," 由状态机解释
 }
 
-type ApplyMsg struct {
-	CommandValid bool
-	Command      interface{}
-	CommandIndex int32
+// 日志在AppendEntries中的表示
+func (l Log) toEntry(index int32) *RPC.Entry {
+	return &RPC.Entry{Term: l.Term, Index: index, Type: l.Type, Data: l.Data}
+}
+
+func fromEntries(entries []*RPC.Entry) []Log {
+	logs := make([]Log, len(entries))
+	for i, e := range entries {
+		logs[i] = Log{Term: e.Term, Type: e.Type, Data: e.Data}
+	}
+	return logs
 }
 
 type Raft struct {
@@ -56,6 +62,8 @@ type Raft struct {
 	currentTerm int32 // "latest term server has seen (initialized to 0 increases monotonically)"
 	votedFor    int32 // "candidateId that received vote in current term (or null if none)"
 	log         []Log // "log entries;(first index is 1)"
+	// 快照包含的最后一条日志的index，rf.log[0]对应这条日志(Term为lastIncludedTerm)
+	lastIncludedIndex int32
 
 	//Volatile state on all servers:
 	commitIndex int32 // "index of highest log entry known to be committed (initialized to 0, increases monotonically)"
@@ -66,51 +74,102 @@ type Raft struct {
 	matchIndex []int32 // "for each server,index of highest log entry known to be replicated on server(initialized to 0, im)"
 
 	//channel
-	applyCh chan int  // from Make()
-	killCh  chan bool //for Kill()
+	killCh chan bool //for Kill()
 	//handle rpc
 	voteCh      chan bool
 	appendLogCh chan bool
 
 	// New
-	persist *Per.Persister
-	client  RPC.RAFTClient
-	address string
-	members []string
-	delay   int
+	persister *Per.Persister // Raft的持久化状态
+	sm        StateMachine   // 已提交的日志交给状态机apply
+	transport Transport      // 节点之间的RPC
+	clock     Clock
+	sched     Scheduler
+	rand      *rand.Rand // nil表示使用全局随机数
+	randMu    sync.Mutex
+	address   string
+	members   []string        // 当前集群配置，下标即成员id，被移除的成员为空字符串
+	learners  map[string]bool // 当前配置中不参与投票的learner
+	delay     int
+
+	noSendJitter bool // AppendEntries发送前不随机等待0~25ms
+
+	baseMembers  []string // lastIncludedIndex处的集群配置，当前配置=baseMembers+日志中的配置日志
+	baseLearners []string // lastIncludedIndex处配置中的learner
+	addingServer string   // leader正在追赶日志、还不是成员的新节点
+
+	transferTarget string // leader正在把领导权转移给的节点
+
+	lastHeartbeat time.Time // 最后一次收到当前leader消息的时间
+	disconnected  int32     // 测试用，模拟网络分区
+
+	// leader lease：每个成员最近一次成功响应的心跳的发送时间
+	leaseAcks  map[int32]time.Time
+	clockDrift time.Duration // lease为minElectionTimeout减去这个时钟漂移余量
+
+	progress []*progress // leader对每个peer的复制状态(probe/replicate/snapshot)
+
+	proposals map[int32]*Proposal // 等待apply的Proposal，key为日志下标
+
+	dead int32 // set by Kill()
+
+	// 持久化状态超过maxRaftState字节时做快照并丢弃日志前缀，-1表示不做快照
+	maxRaftState  int
+	raftStateSize int
+	// LevelDB中每条日志一个key，persist只写入hard state和从unstable开始的日志
+	unstable   int32 // 第一条还没有写入或者写入之后被修改过的日志的index
+	savedFirst int32 // LevelDB中第一条日志的index
+	savedSizes []int // LevelDB中从savedFirst开始每条日志编码后的大小
+	logBytes   int   // savedSizes之和
+	// follower正在接收的快照chunk
+	snapshotBuf   []byte
+	snapshotIndex int32
 }
 
-//Helper function
-func send(ch chan bool) {
-	select {
-	case <-ch: //if already set, consume it then resent to avoid block
-	default:
-	}
-	ch <- true
+// 需要持久化的状态，在回复任何RPC之前写入LevelDB。
+// 日志不在其中，[LastIncludedIndex, 最后一条日志]中的每一条保存在各自的key下
+type persistentState struct {
+	CurrentTerm       int32
+	VotedFor          int32
+	LastIncludedIndex int32
+	Members           []string // lastIncludedIndex处的集群配置
+	Learners          []string // lastIncludedIndex处配置中的learner
 }
 
+// 选举超时为[minElectionTimeout, minElectionTimeout+350ms)
+const minElectionTimeout = 500 * time.Millisecond
+
+// 快照分chunk发送，每个chunk的最大字节数
+const snapshotChunkSize = 64 * 1024
+
 func (rf *Raft) getPrevLogIdx(i int) int32 {
 	return rf.nextIndex[i] - 1
 }
 
 func (rf *Raft) getPrevLogTerm(i int) int32 {
 	prevLogIdx := rf.getPrevLogIdx(i)
-	if prevLogIdx < 0 {
+	if prevLogIdx < rf.lastIncludedIndex {
 		return -1
 	}
-	return rf.log[prevLogIdx].Term
+	return rf.logAt(prevLogIdx).Term
 }
 
 func (rf *Raft) getLastLogIdx() int32 {
-	return int32(len(rf.log) - 1)
+	return rf.lastIncludedIndex + int32(len(rf.log)-1)
 }
 
 func (rf *Raft) getLastLogTerm() int32 {
-	idx := rf.getLastLogIdx()
-	if idx < 0 {
-		return -1
-	}
-	return rf.log[idx].Term
+	return rf.logAt(rf.getLastLogIdx()).Term
+}
+
+// 日志的index是全局的，快照之后需要减去lastIncludedIndex才是rf.log的下标
+func (rf *Raft) logAt(index int32) Log {
+	return rf.log[index-rf.lastIncludedIndex]
+}
+
+// 返回[from, 最后一条日志]，from必须大于lastIncludedIndex
+func (rf *Raft) logFrom(from int32) []Log {
+	return rf.log[from-rf.lastIncludedIndex:]
 }
 
 // Add to sort int32
@@ -120,17 +179,23 @@ func (s IntSlice) Len() int           { return len(s) }
 func (s IntSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 func (s IntSlice) Less(i, j int) bool { return s[i] < s[j] }
 
-//If there exists an N such that N > commitIndex,
+// If there exists an N such that N > commitIndex,
 // a majority of matchIndex[i] ≥ N, and log[N].term == currentTerm: set commitIndex = N (§5.3, §5.4).
 func (rf *Raft) updateCommitIndex() {
-	// rf.matchIndex[rf.me] =int32( len(rf.log) - 1)
-	copyMatchIndex := make([]int32, len(rf.matchIndex))
-	//fmt.Println(rf.matchIndex)
-	copy(copyMatchIndex, rf.matchIndex)
+	// 只统计当前配置中参与投票的成员，learner、正在追赶日志的新节点和已经移除的成员不参与
+	copyMatchIndex := make([]int32, 0, len(rf.members))
+	for i := range rf.members {
+		if rf.isVoter(i) {
+			copyMatchIndex = append(copyMatchIndex, rf.matchIndex[i])
+		}
+	}
+	if len(copyMatchIndex) == 0 {
+		return
+	}
 	sort.Sort(sort.Reverse(IntSlice(copyMatchIndex)))
-	N := int32(copyMatchIndex[(len(copyMatchIndex)-1)/2])
+	N := int32(copyMatchIndex[len(copyMatchIndex)/2])
 	//fmt.Println("N: ", N)
-	if N > rf.commitIndex && rf.log[N].Term == rf.currentTerm {
+	if N > rf.commitIndex && rf.logAt(N).Term == rf.currentTerm {
 		rf.commitIndex = N
 		rf.updateLastApplied()
 	}
@@ -150,115 +215,187 @@ type Op struct {
 func (rf *Raft) updateLastApplied() {
 	for rf.lastApplied < rf.commitIndex {
 		rf.lastApplied++
-		curLog := rf.log[rf.lastApplied]
-		m := curLog.Command //.(config.Op)
-		if m.Option == "Put" {
-			fmt.Println("Put key: ", m.Key, ",value: ", m.Value)
-			rf.persist.Put(m.Key, m.Value)
-			if rf.state == Leader {
-				// leader apply
-				fmt.Println("Leader apply log, Index: ", rf.commitIndex)
-				rf.applyCh <- 1
-			}
+		curLog := rf.logAt(rf.lastApplied)
+		msg := ApplyMsg{
+			CommandValid: curLog.Type == RPC.EntryType_EntryNormal,
+			CommandIndex: rf.lastApplied,
+			CommandTerm:  curLog.Term,
+		}
+		if msg.CommandValid {
+			msg.Command = curLog.Data
+		}
+		result := rf.sm.Apply(msg)
+		rf.resolveProposal(rf.lastApplied, curLog.Term, result)
+	}
+	rf.maybeSnapshot()
+}
+
+// save Raft's persistent state to stable storage,
+// where it can later be retrieved after a crash and restart.
+// 同步写入hard state和新的或者被修改过的日志，删除被截断和被快照丢弃的日志，
+// 已经写入的日志不会重新编码和写入。
+// 调用者需要持有rf.mu
+func (rf *Raft) persist() {
+	if rf.killed() {
+		// Kill()之后persister可能已经被关闭
+		return
+	}
+	first, last := rf.lastIncludedIndex, rf.getLastLogIdx()
+	from := rf.unstable
+	if from < first {
+		from = first
+	}
+	var b Per.Batch
+	state := rf.encodeState()
+	b.SetRaftState(state)
+	// 删除快照之前的日志；从from开始的旧日志被新日志覆盖，超出最后一条的被删除
+	savedLast := rf.savedFirst + int32(len(rf.savedSizes)) - 1
+	for i := rf.savedFirst; i <= savedLast && i < first; i++ {
+		rf.logBytes -= rf.savedSizes[i-rf.savedFirst]
+		b.DeleteRaftEntry(int64(i))
+	}
+	for i := savedLast; i >= from && i >= first; i-- {
+		rf.logBytes -= rf.savedSizes[i-rf.savedFirst]
+		if i > last {
+			b.DeleteRaftEntry(int64(i))
+		}
+	}
+	rf.savedSizes = rf.savedSizes[clampIndex(first-rf.savedFirst, len(rf.savedSizes)):clampIndex(from-rf.savedFirst, len(rf.savedSizes))]
+	for i := from; i <= last; i++ {
+		data := encodeLog(rf.logAt(i), i)
+		b.PutRaftEntry(int64(i), data)
+		rf.savedSizes = append(rf.savedSizes, len(data))
+		rf.logBytes += len(data)
+	}
+	rf.savedFirst, rf.unstable = first, last+1
+	rf.raftStateSize = len(state) + rf.logBytes
+	rf.persister.SaveRaftState(&b)
+}
+
+// 把i限制在[0, n]中
+func clampIndex(i int32, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if int(i) > n {
+		return n
+	}
+	return int(i)
+}
+
+// 日志从index开始被截断或者替换，下一次persist需要重新写入
+func (rf *Raft) logChanged(index int32) {
+	if index < rf.unstable {
+		rf.unstable = index
+	}
+}
+
+func (rf *Raft) encodeState() []byte {
+	data, err := json.Marshal(persistentState{
+		CurrentTerm:       rf.currentTerm,
+		VotedFor:          rf.votedFor,
+		LastIncludedIndex: rf.lastIncludedIndex,
+		Members:           rf.baseMembers,
+		Learners:          rf.baseLearners,
+	})
+	if err != nil {
+		log.Fatalf("persist failed: %v", err)
+	}
+	return data
+}
+
+func encodeLog(l Log, index int32) []byte {
+	data, err := proto.Marshal(l.toEntry(index))
+	if err != nil {
+		log.Fatalf("encode log failed: %v", err)
+	}
+	return data
+}
+
+// restore previously persisted state.
+func (rf *Raft) readPersist(data []byte) {
+	if len(data) == 0 { // bootstrap without any state
+		return
+	}
+	var ps persistentState
+	if err := json.Unmarshal(data, &ps); err != nil {
+		log.Fatalf("readPersist failed: %v", err)
+	}
+	rf.currentTerm = ps.CurrentTerm
+	rf.votedFor = ps.VotedFor
+	rf.lastIncludedIndex = ps.LastIncludedIndex
+	rf.baseMembers = ps.Members
+	rf.baseLearners = ps.Learners
+	rf.log = nil
+	rf.savedFirst, rf.savedSizes, rf.logBytes = rf.lastIncludedIndex, nil, 0
+	rf.persister.ReadRaftLog(func(index int64, value []byte) {
+		if want := int64(rf.lastIncludedIndex) + int64(len(rf.log)); index != want {
+			log.Fatalf("readPersist failed: log entry %d, want %d", index, want)
 		}
+		var entry RPC.Entry
+		if err := proto.Unmarshal(value, &entry); err != nil {
+			log.Fatalf("readPersist failed: %v", err)
+		}
+		rf.log = append(rf.log, Log{Term: entry.Term, Type: entry.Type, Data: entry.Data})
+		rf.savedSizes = append(rf.savedSizes, len(value))
+		rf.logBytes += len(value)
+	})
+	if len(rf.log) == 0 {
+		log.Fatalf("readPersist failed: missing log entry %d", rf.lastIncludedIndex)
 	}
+	rf.unstable = rf.getLastLogIdx() + 1
+	// 快照之前的日志已经apply到LevelDB中
+	rf.commitIndex = rf.lastIncludedIndex
+	rf.lastApplied = rf.lastIncludedIndex
+	rf.raftStateSize = len(data) + rf.logBytes
+	util.DPrintf("[%v] restore term %d, votedFor %d, snapshot index %d, %d logs", rf.address, rf.currentTerm, rf.votedFor, rf.lastIncludedIndex, len(rf.log)-1)
 }
 
 func (rf *Raft) RequestVote(ctx context.Context, args *RPC.RequestVoteArgs) (*RPC.RequestVoteReply, error) {
-	/*  rf.mu.Lock()
-	defer rf.mu.Unlock() */
+	if !rf.connected() {
+		return nil, errDisconnected
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	reply := &RPC.RequestVoteReply{}
+	if !args.LeadershipTransfer && (rf.state == Leader || rf.clock.Now().Sub(rf.lastHeartbeat) < minElectionTimeout) {
+		// 最小选举超时内收到过当前leader的消息，说明leader还在，忽略投票请求并且不更新term，
+		// 防止已经被移出集群(不知道自己被移除)的节点不断发起选举打断集群。
+		// leader主动转移领导权时发起的选举不受这个限制
+		reply.Term = rf.currentTerm
+		return reply, nil
+	}
 	if args.Term > rf.currentTerm { //all server rule 1 If RPC request or response contains term T > currentTerm:
 		rf.beFollower(args.Term) // set currentTerm = T, convert to follower (§5.1)
 	}
-	reply := &RPC.RequestVoteReply{}
 	reply.Term = rf.currentTerm
 	reply.VoteGranted = false
 	if (args.Term < rf.currentTerm) || (rf.votedFor != NULL && rf.votedFor != args.CandidateId) {
 		// Reply false if term < currentTerm (§5.1)  If votedFor is not null and not candidateId,
-	} else if args.LastLogTerm < rf.getLastLogTerm() || (args.LastLogTerm == rf.getLastLogTerm() && args.LastLogIndex < rf.getLastLogIdx()) {
-		//If the logs have last entries with different terms, then the log with the later term is more up-to-date.
-		// If the logs end with the same term, then whichever log is longer is more up-to-date.
+	} else if !rf.isLogUpToDate(args.LastLogIndex, args.LastLogTerm) {
 		// Reply false if candidate’s log is at least as up-to-date as receiver’s log
 	} else {
 		//grant vote
 		rf.votedFor = args.CandidateId
 		reply.VoteGranted = true
 		rf.state = Follower
-		// rf.persist()
-		send(rf.voteCh) //because If election timeout elapses without receiving granting vote to candidate, so wake up
+		rf.persist()
+		rf.send(rf.voteCh) //because If election timeout elapses without receiving granting vote to candidate, so wake up
 
 	}
-	// Debug TODO......
-	reply.VoteGranted = true
 	return reply, nil
 }
 
-//Leader Section:
-func (rf *Raft) startAppendLog() {
-	for i := 0; i < len(rf.members); i++ {
-		go func(idx int) {
-			for {
-				rf.mu.Lock()
-				if rf.state != Leader {
-					rf.mu.Unlock()
-					return
-				} //send initial empty AppendEntries RPCs (heartbeat) to each server
-
-				appendLog := rf.log[rf.nextIndex[idx]:]
-				data, _ := json.Marshal(appendLog)
-
-				args := RPC.AppendEntriesArgs{
-					Term:         rf.currentTerm,
-					LeaderId:     rf.me,
-					PrevLogIndex: rf.getPrevLogIdx(idx),
-					PrevLogTerm:  rf.getPrevLogTerm(idx),
-					//If last log index ≥ nextIndex for a follower:send AppendEntries RPC with log entries starting at nextIndex
-					//nextIndex > last log index, rf.log[rf.nextIndex[idx]:] will be empty then like a heartbeat
-					Log:          data,
-					LeaderCommit: rf.commitIndex,
-				}
-				rf.mu.Unlock()
-				//:= &RPC.AppendEntriesReply{}
-				util.DPrintf("[%v] (term %d, state %d) send logs from index:%v to server:%v", rf.address, rf.currentTerm, rf.state, rf.nextIndex[idx], idx)
-				reply, ret := rf.sendAppendEntries(rf.members[idx], &args)
-				rf.mu.Lock()
-				if !ret || rf.state != Leader || rf.currentTerm != args.Term {
-					rf.mu.Unlock()
-					return
-				}
-				if reply.Term > rf.currentTerm { //all server rule 1 If RPC response contains term T > currentTerm:
-					rf.beFollower(reply.Term) // set currentTerm = T, convert to follower (§5.1)
-					rf.mu.Unlock()
-					return
-				}
-				if reply.Success { //If successful：update nextIndex and matchIndex for follower
-					rf.matchIndex[idx] = args.PrevLogIndex + int32(len(appendLog))
-					rf.nextIndex[idx] = rf.matchIndex[idx] + 1
-					rf.updateCommitIndex()
-					rf.mu.Unlock()
-					return
-				} else { //If AppendEntries fails because of log inconsistency: decrement nextIndex and retry
-					tarIndex := reply.ConflictIndex //If it does not find an entry with that term
-					if reply.ConflictTerm != NULL {
-						logSize := len(rf.log)         //first search its log for conflictTerm
-						for i := 0; i < logSize; i++ { //if it finds an entry in its log with that term,
-							if rf.log[i].Term != reply.ConflictTerm {
-								continue
-							}
-							for i < logSize && rf.log[i].Term == reply.ConflictTerm {
-								i++
-							} //set nextIndex to be the one
-							tarIndex = int32(i) //beyond the index of the last entry in that term in its log
-						}
-					}
-					rf.nextIndex[idx] = tarIndex
-					rf.mu.Unlock()
-				}
-			}
-		}(i)
+// If the logs have last entries with different terms, then the log with the later term is more up-to-date.
+// If the logs end with the same term, then whichever log is longer is more up-to-date.
+func (rf *Raft) isLogUpToDate(lastLogIndex int32, lastLogTerm int32) bool {
+	if lastLogTerm != rf.getLastLogTerm() {
+		return lastLogTerm > rf.getLastLogTerm()
 	}
+	return lastLogIndex >= rf.getLastLogIdx()
 }
 
+//Leader Section:
 // Leader Section:
 // func (rf *Raft) sendHeartBeat() {
 // 	fmt.Println("send heart beat")
@@ -292,20 +429,24 @@ func (rf *Raft) startAppendLog() {
 
 func (rf *Raft) sendAppendEntries(address string, args *RPC.AppendEntriesArgs) (*RPC.AppendEntriesReply, bool) {
 	// 随机等待，模拟延迟
-	time.Sleep(time.Millisecond * time.Duration(rf.delay+rand.Intn(25)))
+	if !rf.noSendJitter {
+		rf.sleep(time.Millisecond * time.Duration(rf.delay+rf.randIntn(25)))
+	}
+	if !rf.connected() {
+		return nil, false
+	}
 
-	// grpc.Dial默认建立连接是异步的，加了这个WithBlock()参数后会等待所有连接建立成功后再返回
-	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock())
+	// follower下线时连接不可用，RPC立即返回错误，复制循环在下一次心跳时重试，不会一直阻塞在途窗口
+	client, err := rf.transport.Client(address)
 	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+		util.DPrintf("sendAppendEntries did not connect: %v %v", err, address)
+		return nil, false
 	}
-	defer conn.Close()
-	rf.client = RPC.NewRAFTClient(conn)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 	//args := &RPC.AppendEntriesArgs{}
-	reply, err := rf.client.AppendEntries(ctx, args)
+	reply, err := client.AppendEntries(ctx, args)
 	if err != nil {
 		fmt.Println(" sendAppendEntries could not greet: ", err, address)
 		return reply, false
@@ -313,12 +454,15 @@ func (rf *Raft) sendAppendEntries(address string, args *RPC.AppendEntriesArgs) (
 	return reply, true
 }
 
-//AppendEntries RPC handler.
+// AppendEntries RPC handler.
 func (rf *Raft) AppendEntries(ctx context.Context, args *RPC.AppendEntriesArgs) (*RPC.AppendEntriesReply, error) { //now only for heartbeat
+	if !rf.connected() {
+		return nil, errDisconnected
+	}
 	rf.mu.Lock()
 	defer rf.mu.Unlock()
 	//	time.Sleep(time.Millisecond * time.Duration(rf.delay))
-	defer send(rf.appendLogCh)      //If election timeout elapses without receiving AppendEntries RPC from current leader
+	defer rf.send(rf.appendLogCh)   //If election timeout elapses without receiving AppendEntries RPC from current leader
 	if args.Term > rf.currentTerm { //all server rule 1 If RPC request or response contains term T > currentTerm:
 		rf.beFollower(args.Term) // set currentTerm = T, convert to follower (§5.1)
 	}
@@ -328,11 +472,34 @@ func (rf *Raft) AppendEntries(ctx context.Context, args *RPC.AppendEntriesArgs)
 	reply.Success = false
 	reply.ConflictTerm = NULL
 	reply.ConflictIndex = 0
+	//2. Reply false if term < currentTerm (§5.1)
+	if args.Term < rf.currentTerm {
+		return reply, nil
+	}
+	rf.lastHeartbeat = rf.clock.Now()
+	if rf.state == Candidate || rf.state == PreCandidate {
+		// 收到当前term leader的日志，说明已经有leader
+		rf.state = Follower
+	}
+
+	log := fromEntries(args.Entries)
+	if args.PrevLogIndex < rf.lastIncludedIndex {
+		// 快照中的日志一定已经提交，跳过这部分日志
+		skip := rf.lastIncludedIndex - args.PrevLogIndex
+		if int(skip) >= len(log) {
+			reply.Success = true
+			return reply, nil
+		}
+		log = log[skip:]
+		args.PrevLogIndex = rf.lastIncludedIndex
+		args.PrevLogTerm = rf.log[0].Term
+	}
+
 	//1. Reply false if log doesn’t contain an entry at prevLogIndex whose term matches prevLogTerm (§5.3)
 	var prevLogIndexTerm int32 = -1
-	var logSize int32 = int32(len(rf.log))
-	if args.PrevLogIndex >= 0 && args.PrevLogIndex < int32(len(rf.log)) {
-		prevLogIndexTerm = rf.log[args.PrevLogIndex].Term
+	var logSize int32 = rf.getLastLogIdx() + 1
+	if args.PrevLogIndex >= rf.lastIncludedIndex && args.PrevLogIndex < logSize {
+		prevLogIndexTerm = rf.logAt(args.PrevLogIndex).Term
 	}
 	if prevLogIndexTerm != args.PrevLogTerm {
 		// prevLog不对，就返回信息让Leader重新确定log
@@ -341,9 +508,9 @@ func (rf *Raft) AppendEntries(ctx context.Context, args *RPC.AppendEntriesArgs)
 			//it should return with conflictIndex = len(log) and conflictTerm = None.
 		} else { //If a follower does have prevLogIndex in its log, but the term does not match
 			reply.ConflictTerm = prevLogIndexTerm //it should return conflictTerm = log[prevLogIndex].Term,
-			i := int32(0)
+			i := rf.lastIncludedIndex + 1
 			for ; i < logSize; i++ { //and then search its log for
-				if rf.log[i].Term == reply.ConflictTerm { //the first index whose entry has term equal to conflictTerm
+				if rf.logAt(i).Term == reply.ConflictTerm { //the first index whose entry has term equal to conflictTerm
 					reply.ConflictIndex = i
 					break
 				}
@@ -352,36 +519,42 @@ func (rf *Raft) AppendEntries(ctx context.Context, args *RPC.AppendEntriesArgs)
 		return reply, nil
 	}
 
-	var log []Log
-	json.Unmarshal(args.Log, &log)
-
-	//2. Reply false if term < currentTerm (§5.1)
-	if args.Term < rf.currentTerm {
-		return reply, nil
-	}
-
 	index := args.PrevLogIndex
 	for i := 0; i < len(log); i++ {
 		index++
+		configChanged := false
 		if index < logSize {
 			// 一样的log会被跳过
-			if rf.log[index].Term == log[i].Term {
+			if rf.logAt(index).Term == log[i].Term {
 				continue
 			} else { //3. If an existing entry conflicts with a new one (same index but different terms),
 				// 把不一样的日志及后面的所有日志删除
-				rf.log = rf.log[:index] //delete the existing entry and all that follow it (§5.3)
+				for j := index; j < logSize; j++ {
+					configChanged = configChanged || isConfigChange(rf.logAt(j))
+				}
+				rf.log = rf.log[:index-rf.lastIncludedIndex] //delete the existing entry and all that follow it (§5.3)
+				rf.logChanged(index)
 			}
 		}
 		// 检测到log不一致时才会运行到这
 		util.DPrintf("[%v] (term %d, state %d) append %v logs from index:%v", rf.address, rf.currentTerm, rf.state, len(log)-i, index)
 		rf.log = append(rf.log, log[i:]...) //4. Append any new entries not already in the log
-		// rf.persist()
+		for _, l := range log[i:] {
+			configChanged = configChanged || isConfigChange(l)
+		}
+		if configChanged {
+			// 配置日志追加之后立即生效，被截断时回退到之前的配置
+			rf.reloadConfig()
+			util.DPrintf("[%v] (term %d, state %d) members changed: %v", rf.address, rf.currentTerm, rf.state, rf.members)
+		}
+		rf.persist()
 		//fmt.Println(args.Term,"Append RAft Log ",rf.log)
 		break
 	}
 	//5. If leaderCommit > commitIndex, set commitIndex = min(leaderCommit, index of last new entry)
-	if args.LeaderCommit > rf.commitIndex {
-		rf.commitIndex = Min(args.LeaderCommit, rf.getLastLogIdx())
+	// 必须用这次RPC中最后一条日志的index，本地在它之后的日志可能还没有和leader对齐
+	if lastNewIndex := args.PrevLogIndex + int32(len(log)); args.LeaderCommit > rf.commitIndex && lastNewIndex > rf.commitIndex {
+		rf.commitIndex = Min(args.LeaderCommit, lastNewIndex)
 		rf.updateLastApplied()
 	}
 	reply.Success = true
@@ -395,30 +568,14 @@ func Min(a int32, b int32) int32 {
 	return a
 }
 
-func (rf *Raft) RegisterServer(address string) {
-	// Register Server
-	for {
-		lis, err := net.Listen("tcp", address)
-		if err != nil {
-			log.Fatalf("failed to listen: %v", err)
-		}
-		s := grpc.NewServer()
-		RPC.RegisterRAFTServer(s, rf)
-		// Register reflection service on gRPC server.
-		reflection.Register(s)
-		if err := s.Serve(lis); err != nil {
-			fmt.Printf("failed to serve: %v \n", err)
-		}
-	}
-
-}
-
-//Follower Section:
+// Follower Section:
 func (rf *Raft) beFollower(term int32) {
 	rf.state = Follower
+	rf.leaseAcks = nil
+	rf.transferTarget = ""
 	rf.votedFor = NULL
 	rf.currentTerm = term
-	//rf.persist()
+	rf.persist()
 }
 
 func (rf *Raft) beLeader() {
@@ -427,24 +584,93 @@ func (rf *Raft) beLeader() {
 		return
 	}
 	rf.state = Leader
+	rf.leaseAcks = make(map[int32]time.Time)
+	rf.transferTarget = ""
+	rf.progress = nil
 	//initialize leader data
 	rf.nextIndex = make([]int32, len(rf.members))
 	rf.matchIndex = make([]int32, len(rf.members))
 	for i := 0; i < len(rf.nextIndex); i++ { //(initialized to leader last log index + 1)
 		rf.nextIndex[i] = rf.getLastLogIdx() + 1
 	}
+	// 新leader先提交一条当前term的空日志，之前term的日志随之提交，
+	// 在这之前不允许配置变更
+	rf.log = append(rf.log, Log{Term: rf.currentTerm, Type: RPC.EntryType_EntryNoOp})
+	rf.persist()
 	util.DPrintf("[%v] (term %d, state %d) has been new Leader!", rf.address, rf.currentTerm, rf.state)
 }
 
-//Candidate Section:
+// Candidate Section:
 // If AppendEntries RPC received from new leader: convert to follower implemented in AppendEntries RPC Handler
-func (rf *Raft) beCandidate() { //Reset election timer are finished in caller
+// transfer表示这次选举是由leader的TimeoutNow触发的领导权转移
+func (rf *Raft) beCandidate(transfer bool) { //Reset election timer are finished in caller
 	util.DPrintf("[%v] (term %d, state %d) is becoming Candidate!", rf.address, rf.currentTerm, rf.state)
 	rf.state = Candidate
 	rf.currentTerm++    //Increment currentTerm
 	rf.votedFor = rf.me //vote myself first
+	rf.persist()
 	//ask for other's vote
-	go rf.startElection() //Send RequestVote RPCs to all other servers
+	rf.sched.Go(func() { rf.startElection(transfer) }) //Send RequestVote RPCs to all other servers
+}
+
+// the tester doesn't halt goroutines created by Raft after each test,
+// but it does call the Kill() method. Kill stops the election loop and
+// the transport so that the same address can be restarted later.
+func (rf *Raft) Kill() {
+	atomic.StoreInt32(&rf.dead, 1)
+	rf.send(rf.killCh)
+	rf.transport.Close()
+	rf.mu.Lock()
+	rf.abortProposals()
+	rf.mu.Unlock()
+}
+
+func (rf *Raft) killed() bool {
+	return atomic.LoadInt32(&rf.dead) == 1
+}
+
+var errDisconnected = errors.New("disconnected")
+
+// SetConnected is used by tests to simulate a network partition:
+// a disconnected server neither sends nor handles any Raft RPC.
+func (rf *Raft) SetConnected(connected bool) {
+	if connected {
+		atomic.StoreInt32(&rf.disconnected, 0)
+	} else {
+		atomic.StoreInt32(&rf.disconnected, 1)
+	}
+}
+
+func (rf *Raft) connected() bool {
+	return atomic.LoadInt32(&rf.disconnected) == 0
+}
+
+// LogState is a read-only copy of a server's log, used by tests to check
+// Raft's safety properties.
+type LogState struct {
+	Term        int32
+	IsLeader    bool
+	FirstIndex  int32 // Entries[0]的下标，之前的日志已经在快照中
+	Entries     []*RPC.Entry
+	CommitIndex int32
+	LastApplied int32
+}
+
+func (rf *Raft) LogState() LogState {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	first := rf.lastIncludedIndex + 1
+	st := LogState{
+		Term:        rf.currentTerm,
+		IsLeader:    rf.state == Leader,
+		FirstIndex:  first,
+		CommitIndex: rf.commitIndex,
+		LastApplied: rf.lastApplied,
+	}
+	for i, l := range rf.logFrom(first) {
+		st.Entries = append(st.Entries, l.toEntry(first+int32(i)))
+	}
+	return st
 }
 
 func (rf *Raft) GetState() (int32, bool) {
@@ -457,28 +683,45 @@ func (rf *Raft) GetState() (int32, bool) {
 	return term, isleader
 }
 
-//If election timeout elapses: start new election handled in caller
-func (rf *Raft) startElection() {
+// If election timeout elapses: start new election handled in caller
+func (rf *Raft) startElection(transfer bool) {
 	fmt.Println("startElection")
+	rf.mu.Lock()
 	args := RPC.RequestVoteArgs{
-		Term:         rf.currentTerm,
-		CandidateId:  rf.me,
-		LastLogIndex: rf.getLastLogIdx(),
-		LastLogTerm:  rf.getLastLogTerm(),
+		Term:               rf.currentTerm,
+		CandidateId:        rf.me,
+		LastLogIndex:       rf.getLastLogIdx(),
+		LastLogTerm:        rf.getLastLogTerm(),
+		LeadershipTransfer: transfer,
+	}
+	// 只向参与投票的成员请求投票
+	var members []string
+	for i, m := range rf.members {
+		if rf.isVoter(i) {
+			members = append(members, m)
+		}
 	}
+	quorum := int32(rf.numVoters()/2 + 1)
 	var votes int32 = 1
-	for i := 0; i < len(rf.members); i++ {
-		if rf.address == rf.members[i] {
+	if votes >= quorum {
+		// 集群中只剩自己
+		rf.beLeader()
+		rf.send(rf.voteCh)
+	}
+	rf.mu.Unlock()
+	for i := 0; i < len(members); i++ {
+		if rf.address == members[i] || members[i] == "" {
 			continue
 		}
-		go func(idx int) {
+		idx := i
+		rf.sched.Go(func() {
 			//fmt.Println("sendRequestVote to :", rf.members[idx])
 			//reply := RPC.RequestVoteReply{Term:9999, VoteGranted: false}
-			ret, reply := rf.sendRequestVote(rf.members[idx], &args /* ,&reply */)
+			ret, reply := rf.sendRequestVote(members[idx], &args /* ,&reply */)
 
 			if ret {
-				/* rf.mu.Lock()
-				   defer rf.mu.Unlock() */
+				rf.mu.Lock()
+				defer rf.mu.Unlock()
 				if reply.Term > rf.currentTerm {
 					//fmt.Println( "reply.beFollower ")
 					rf.beFollower(reply.Term)
@@ -494,13 +737,13 @@ func (rf *Raft) startElection() {
 				} else {
 					//	fmt.Println( "#########reply.VoteGranted false ", reply.Term)
 				}
-				if atomic.LoadInt32(&votes) > int32(len(rf.members)/2) {
+				if atomic.LoadInt32(&votes) == quorum {
 					rf.beLeader()
 					//fmt.Println("rf.beLeader()")
-					send(rf.voteCh) //after be leader, then notify 'select' goroutine will sending out heartbeats immediately
+					rf.send(rf.voteCh) //after be leader, then notify 'select' goroutine will sending out heartbeats immediately
 				}
 			}
-		}(i)
+		})
 
 	}
 }
@@ -511,85 +754,121 @@ func (rf *Raft) init() {
 	rf.currentTerm = 0
 	rf.votedFor = -1
 	rf.log = make([]Log, 1) //(first index is 1)
+	rf.proposals = make(map[int32]*Proposal)
 
 	rf.commitIndex = 0
 	rf.lastApplied = 0
+	// 重启的节点可能在crash前刚响应过leader的心跳，等待一个最小选举超时之后才投票，保证leader lease有效
+	rf.lastHeartbeat = rf.clock.Now()
+	// 从LevelDB恢复crash前的term、votedFor、快照位置和log
+	rf.readPersist(rf.persister.ReadRaftState())
+	rf.reloadConfig()
 
 	//because gorountine only send the chan to below goroutine,to avoid block, need 1 buffer
 	rf.voteCh = make(chan bool, 1)
 	rf.appendLogCh = make(chan bool, 1)
 	rf.killCh = make(chan bool, 1)
 
-	heartbeatTime := time.Duration(150) * time.Millisecond
-	go func() {
+	rf.sched.Go(func() {
 		for {
-			select {
 			// killCh用来kill raft实例
-			case <-rf.killCh:
+			if rf.sched.Wait(0, rf.killCh) == 0 {
 				return
-			default:
 			}
 			// 选举时间500~850ms
-			electionTime := time.Duration(rand.Intn(350)+500) * time.Millisecond
+			electionTime := minElectionTimeout + time.Duration(rf.randIntn(350))*time.Millisecond
 
-			// rf.mu.Lock()
+			rf.mu.Lock()
 			state := rf.state
-			// rf.mu.Unlock()
+			rf.mu.Unlock()
 			switch state {
-			case Follower, Candidate:
-				select {
-				case <-rf.voteCh:
-				case <-rf.appendLogCh:
-					// 选举时间超时，则会将自身状态转为候选者开始选举
-				case <-time.After(electionTime):
-					//    rf.mu.Lock()
+			case Follower, Candidate, PreCandidate:
+				// 选举时间超时，则会将自身状态转为候选者开始选举
+				if rf.sched.Wait(electionTime, rf.voteCh, rf.appendLogCh) < 0 {
+					rf.mu.Lock()
 					util.DPrintf("Election timeout!")
-					rf.beCandidate() //becandidate, Reset election timer, then start election
-					//    rf.mu.Unlock()
+					if !rf.killed() && rf.isVoter(int(rf.me)) {
+						// 不在当前配置中的节点(新加入还没追上或已经被移除)和learner不参与选举
+						// 先进行PreVote，多数节点同意之后才增加term真正开始选举
+						rf.bePreCandidate()
+					}
+					rf.mu.Unlock()
 				}
 			case Leader:
 				rf.startAppendLog()
-				time.Sleep(heartbeatTime)
+				rf.sleep(heartbeatTime)
 			}
 		}
-	}()
-	go rf.RegisterServer(rf.address)
+	})
+	rf.sched.Go(func() { rf.transport.Serve(rf.address, rf) })
+
+}
 
+// 命令在日志中的编码：实现了Command的命令(例如KV的config.Op)自己编码，也可以直接提交[]byte
+func encodeCommand(command interface{}) []byte {
+	switch c := command.(type) {
+	case Command:
+		return c.Encode()
+	case []byte:
+		return c
+	}
+	panic(fmt.Sprintf("unsupported command type %T", command))
 }
 
 func (rf *Raft) Start(command interface{}) (int32, int32, bool) {
-	rf.mu.Lock()
-	defer rf.mu.Unlock()
-	var index int32 = -1
-	var term int32 = rf.currentTerm
-	isLeader := (rf.state == Leader)
+	index, term, _ := rf.start(command, false)
+	isLeader := index >= 0
 	if isLeader {
-		index = rf.getLastLogIdx() + 1
-		newLog := Log{
-			rf.currentTerm,
-			command.(config.Op),
-		}
-		rf.log = append(rf.log, newLog)
-		// rf.persist()
+		// startAppendLog需要获取rf.mu
 		rf.startAppendLog()
 	}
 	//fmt.Println("new Log",  rf.log)
 	return index, term, isLeader
 }
 
+// 把命令追加到leader的日志，不是leader时index为-1。track为true时返回等待这条日志apply的Proposal
+func (rf *Raft) start(command interface{}, track bool) (int32, int32, *Proposal) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	var index int32 = -1
+	var term int32 = rf.currentTerm
+	// 领导权转移期间不再接受新的请求，让目标节点尽快追上日志
+	if rf.state != Leader || rf.transferTarget != "" || rf.killed() {
+		return index, term, nil
+	}
+	index = rf.getLastLogIdx() + 1
+	newLog := Log{
+		Term: rf.currentTerm,
+		Type: RPC.EntryType_EntryNormal,
+		Data: encodeCommand(command),
+	}
+	rf.log = append(rf.log, newLog)
+	rf.persist()
+	if !track {
+		return index, term, nil
+	}
+	p := &Proposal{Index: index, Term: term, done: make(chan struct{})}
+	if old, ok := rf.proposals[index]; ok {
+		// 之前在这个位置的日志已经被截断
+		old.resolve(ErrProposalDropped)
+	}
+	rf.proposals[index] = p
+	return index, term, p
+}
+
 func (rf *Raft) sendRequestVote(address string, args *RPC.RequestVoteArgs) (bool, *RPC.RequestVoteReply) {
-	//fmt.
-	// Initialize Client
-	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock())
+	if !rf.connected() {
+		return false, nil
+	}
+	client, err := rf.transport.Client(address)
 	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+		util.DPrintf("sendRequestVote did not connect: %v %v", err, address)
+		return false, nil
 	}
-	defer conn.Close()
-	rf.client = RPC.NewRAFTClient(conn)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	reply, err := rf.client.RequestVote(ctx, args)
+	reply, err := client.RequestVote(ctx, args)
 	if err != nil {
 		return false, reply
 	} else {
@@ -598,22 +877,53 @@ func (rf *Raft) sendRequestVote(address string, args *RPC.RequestVoteArgs) (bool
 	}
 }
 
+// 已提交的日志按顺序交给sm；maxRaftState为触发快照的持久化状态大小(字节)，-1表示不做快照；
+// clockDrift为lease read允许的时钟漂移余量
 func MakeRaft(add string, mem []string, persist *Per.Persister,
-	mu *sync.Mutex, applyCh chan int, delay int) *Raft {
+	mu *sync.Mutex, sm StateMachine, delay int, maxRaftState int, clockDrift time.Duration) *Raft {
+	return MakeRaftWithTransport(add, mem, persist, mu, sm, delay, maxRaftState, clockDrift, NewGRPCTransport())
+}
+
+// MakeRaftWithTransport和MakeRaft相同，节点之间通过transport通信
+func MakeRaftWithTransport(add string, mem []string, persist *Per.Persister,
+	mu *sync.Mutex, sm StateMachine, delay int, maxRaftState int, clockDrift time.Duration, transport Transport) *Raft {
+	return MakeRaftWithEnv(add, mem, persist, mu, sm, delay, maxRaftState, clockDrift, Env{Transport: transport})
+}
+
+// MakeRaftWithEnv和MakeRaft相同，网络、时钟和随机数由env提供
+func MakeRaftWithEnv(add string, mem []string, persist *Per.Persister,
+	mu *sync.Mutex, sm StateMachine, delay int, maxRaftState int, clockDrift time.Duration, env Env) *Raft {
 	raft := &Raft{}
-	if len(mem) <= 1 {
+	raft.transport = env.Transport
+	raft.clock = env.Clock
+	if raft.clock == nil {
+		raft.clock = systemClock{}
+	}
+	raft.sched = env.Scheduler
+	if raft.sched == nil {
+		raft.sched = chanScheduler{clock: raft.clock}
+	}
+	raft.rand = env.Rand
+	raft.noSendJitter = env.NoSendJitter
+	if len(mem) <= 1 && len(mem) > 0 && mem[0] == add {
 		panic("#######Address is less 1, you should set follower's address!######")
 	}
 	raft.address = add
-	raft.persist = persist
-	raft.applyCh = applyCh
+	raft.persister = persist
+	raft.sm = sm
 	raft.mu = mu
-	raft.members = make([]string, len(mem))
 	raft.delay = delay
-	for i := 0; i < len(mem); i++ {
-		raft.members[i] = mem[i]
+	raft.maxRaftState = maxRaftState
+	raft.clockDrift = clockDrift
+	raft.baseMembers = make([]string, len(mem))
+	copy(raft.baseMembers, mem)
+	raft.setMembers(raft.baseMembers, make(map[string]bool))
+	if raft.me == NULL {
+		// 不在初始成员中的节点以空配置启动，等待leader通过AddServer把它加入集群
+		util.DPrintf("not in members, waiting to be added by the leader")
+		raft.baseMembers = nil
 	}
-	fmt.Println("members: ", raft.members)
+	fmt.Println("members: ", raft.baseMembers)
 	raft.init()
 	return raft
 }
@@ -0,0 +1,145 @@
+package raft
+
+import (
+	"time"
+
+	RPC "hckvstore/rpc/raftrpc"
+)
+
+// ReadIndex(Raft论文§6.4)：读请求不写入日志。
+// leader把当前commitIndex记为readIndex，通过一轮心跳确认自己仍然是leader，
+// 等lastApplied追上readIndex之后再读状态机，读到的结果满足线性一致性。
+
+const readTimeout = 2 * time.Second
+
+// ReadIndex blocks until it is safe to serve a linearizable read from the
+// local state machine. It returns the read index and false if this server
+// is not the leader or could not confirm its leadership in time.
+func (rf *Raft) ReadIndex() (int32, bool) {
+	rf.mu.Lock()
+	if rf.state != Leader {
+		rf.mu.Unlock()
+		return -1, false
+	}
+	term := rf.currentTerm
+	rf.mu.Unlock()
+
+	// 新leader提交当前term的no-op日志之前，不知道之前term的日志提交到了哪里
+	if !rf.waitCurrentTermCommitted(term) {
+		return -1, false
+	}
+	rf.mu.Lock()
+	readIndex := rf.commitIndex
+	rf.mu.Unlock()
+	if !rf.confirmLeadership(term) {
+		return -1, false
+	}
+	if !rf.waitApplied(readIndex) {
+		return -1, false
+	}
+	return readIndex, true
+}
+
+func (rf *Raft) waitCurrentTermCommitted(term int32) bool {
+	for start := rf.clock.Now(); rf.clock.Now().Sub(start) < readTimeout; {
+		rf.mu.Lock()
+		if rf.state != Leader || rf.currentTerm != term || rf.killed() {
+			rf.mu.Unlock()
+			return false
+		}
+		if rf.logAt(rf.commitIndex).Term == term {
+			rf.mu.Unlock()
+			return true
+		}
+		rf.mu.Unlock()
+		rf.clock.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+// 向所有成员发送一轮心跳，多数成员仍然认可term时的leader才返回true
+func (rf *Raft) confirmLeadership(term int32) bool {
+	rf.mu.Lock()
+	if rf.state != Leader || rf.currentTerm != term {
+		rf.mu.Unlock()
+		return false
+	}
+	quorum := rf.numVoters()/2 + 1
+	acks := 0
+	if rf.isVoter(int(rf.me)) {
+		acks = 1
+	}
+	var slots []int
+	var addresses []string
+	var argsList []*RPC.AppendEntriesArgs
+	for i, address := range rf.members {
+		if !rf.isVoter(i) || int32(i) == rf.me {
+			continue
+		}
+		// 不携带日志，follower只要回复的term和leader相同就说明还认可这个leader
+		slots = append(slots, i)
+		addresses = append(addresses, address)
+		argsList = append(argsList, &RPC.AppendEntriesArgs{
+			Term:         term,
+			LeaderId:     rf.me,
+			PrevLogIndex: rf.getPrevLogIdx(i),
+			PrevLogTerm:  rf.getPrevLogTerm(i),
+			LeaderCommit: rf.commitIndex,
+		})
+	}
+	rf.mu.Unlock()
+	if acks >= quorum {
+		return true
+	}
+
+	ackCh := make(chan bool, len(addresses))
+	sent := rf.clock.Now()
+	for i := range addresses {
+		go func(slot int, address string, args *RPC.AppendEntriesArgs) {
+			reply, ok := rf.sendAppendEntries(address, args)
+			if ok {
+				rf.mu.Lock()
+				if reply.Term > rf.currentTerm {
+					rf.beFollower(reply.Term)
+				} else if reply.Term == term && rf.currentTerm == term {
+					// 确认leader身份的心跳同样可以延长lease
+					rf.recordLeaseAck(slot, sent)
+				}
+				rf.mu.Unlock()
+			}
+			ackCh <- ok && reply.Term == term
+		}(slots[i], addresses[i], argsList[i])
+	}
+	timeout := rf.clock.After(readTimeout)
+	for received := 0; received < len(addresses); received++ {
+		select {
+		case ack := <-ackCh:
+			if ack {
+				acks++
+			}
+			if acks >= quorum {
+				return true
+			}
+		case <-timeout:
+			return false
+		}
+	}
+	return false
+}
+
+func (rf *Raft) waitApplied(index int32) bool {
+	for start := rf.clock.Now(); rf.clock.Now().Sub(start) < readTimeout; {
+		rf.mu.Lock()
+		if rf.killed() {
+			rf.mu.Unlock()
+			return false
+		}
+		if rf.lastApplied >= index {
+			rf.mu.Unlock()
+			return true
+		}
+		rf.mu.Unlock()
+		rf.clock.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
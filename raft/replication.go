@@ -0,0 +1,257 @@
+package raft
+
+import (
+	"time"
+
+	"hckvstore/util"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// leader为每个follower运行一个复制循环，参考etcd的progress tracker：
+// probe状态下不知道follower的日志位置，每次只发送一个AppendEntries，等响应确认匹配位置；
+// replicate状态下乐观地推进nextIndex，不等响应就继续发送后面的日志，最多maxInflight个请求在途；
+// snapshot状态下follower需要的日志已经被丢弃，正在发送快照。
+// 每个AppendEntries最多携带maxBatchEntries条、约maxBatchBytes字节的日志。
+
+const (
+	maxInflight     = 8
+	maxBatchEntries = 256
+	maxBatchBytes   = 1 << 20
+	heartbeatTime   = 150 * time.Millisecond
+)
+
+type progressState int
+
+const (
+	stateProbe progressState = iota
+	stateReplicate
+	stateSnapshot
+)
+
+type progress struct {
+	state    progressState
+	inflight int       // 在途的携带日志的AppendEntries数量(不包括心跳)
+	lastSent time.Time // 最近一次发送AppendEntries的时间
+	running  bool      // 复制循环是否在运行
+	notify   chan bool // 唤醒复制循环
+}
+
+func (pr *progress) becomeProbe() {
+	pr.state = stateProbe
+}
+
+func (pr *progress) becomeReplicate() {
+	pr.state = stateReplicate
+}
+
+// startAppendLog唤醒每个peer的复制循环，没有运行的复制循环会被启动。
+// 心跳和新日志都通过它触发
+func (rf *Raft) startAppendLog() {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.state != Leader || rf.killed() {
+		return
+	}
+	for i := 0; i < len(rf.nextIndex); i++ {
+		if rf.peerAddress(i) == "" {
+			continue
+		}
+		for len(rf.progress) <= i {
+			rf.progress = append(rf.progress, nil)
+		}
+		pr := rf.progress[i]
+		if pr == nil || !pr.running {
+			pr = &progress{running: true, notify: make(chan bool, 1)}
+			rf.progress[i] = pr
+			i, term := i, rf.currentTerm
+			rf.sched.Go(func() { rf.replicate(i, term, pr) })
+		}
+		rf.send(pr.notify)
+	}
+}
+
+// 第idx个peer的复制循环，leader下台、term变化或者peer被移除时退出
+func (rf *Raft) replicate(idx int, term int32, pr *progress) {
+	for {
+		rf.sched.Wait(heartbeatTime, pr.notify)
+		rf.mu.Lock()
+		if !rf.progressValid(idx, term, pr) {
+			pr.running = false
+			rf.mu.Unlock()
+			return
+		}
+		if rf.nextIndex[idx] <= rf.lastIncludedIndex {
+			// follower需要的日志已经被丢弃，改为发送快照
+			pr.state = stateSnapshot
+			rf.mu.Unlock()
+			ok := rf.sendSnapshot(idx)
+			rf.mu.Lock()
+			if ok && rf.progressValid(idx, term, pr) {
+				pr.becomeProbe()
+				rf.send(pr.notify)
+			}
+			rf.mu.Unlock()
+			continue
+		}
+		rf.sendEntries(idx, term, pr)
+		rf.mu.Unlock()
+	}
+}
+
+// 调用者需要持有rf.mu
+func (rf *Raft) progressValid(idx int, term int32, pr *progress) bool {
+	return rf.state == Leader && rf.currentTerm == term && !rf.killed() &&
+		idx < len(rf.nextIndex) && idx < len(rf.progress) && rf.progress[idx] == pr && rf.peerAddress(idx) != ""
+}
+
+// 在流控允许的范围内发送AppendEntries，调用者需要持有rf.mu
+func (rf *Raft) sendEntries(idx int, term int32, pr *progress) {
+	sent := false
+	for rf.nextIndex[idx] <= rf.getLastLogIdx() {
+		if pr.state == stateProbe && pr.inflight > 0 || pr.inflight >= maxInflight {
+			break
+		}
+		args, n := rf.appendEntriesArgs(idx, rf.nextIndex[idx]-1)
+		pr.inflight++
+		if pr.state == stateReplicate {
+			// 乐观推进nextIndex，不等待响应
+			rf.nextIndex[idx] += int32(n)
+		}
+		util.DPrintf("[%v] (term %d, state %d) send %v logs from index:%v to server:%v", rf.address, rf.currentTerm, rf.state, n, args.PrevLogIndex+1, idx)
+		rf.sendAppendAsync(idx, term, pr, args, n)
+		pr.lastSent = rf.clock.Now()
+		sent = true
+		if pr.state == stateProbe {
+			break
+		}
+	}
+	if sent || rf.clock.Now().Sub(pr.lastSent) < heartbeatTime/2 {
+		return
+	}
+	// 心跳
+	if pr.inflight == 0 {
+		args, _ := rf.appendEntriesArgs(idx, rf.nextIndex[idx]-1)
+		rf.sendAppendAsync(idx, term, pr, args, 0)
+	} else if rf.matchIndex[idx] >= rf.lastIncludedIndex {
+		// 在途窗口已满或者在等待probe的响应，在matchIndex处发送空AppendEntries，
+		// 这个位置的日志一定和follower一致，不影响流控
+		args, _ := rf.appendEntriesArgs(idx, rf.matchIndex[idx])
+		args.Entries = nil
+		rf.sendAppendAsync(idx, term, pr, args, -1)
+	} else {
+		return
+	}
+	pr.lastSent = rf.clock.Now()
+}
+
+// 构造从prevLogIndex+1开始的AppendEntries，返回携带的日志条数，调用者需要持有rf.mu
+func (rf *Raft) appendEntriesArgs(idx int, prevLogIndex int32) (*RPC.AppendEntriesArgs, int) {
+	prevLogTerm := int32(-1)
+	if prevLogIndex >= rf.lastIncludedIndex {
+		prevLogTerm = rf.logAt(prevLogIndex).Term
+	}
+	// 按条数和字节数限制一次发送的日志
+	var entries []*RPC.Entry
+	size := 0
+	if prevLogIndex >= rf.lastIncludedIndex {
+		for i, l := range rf.logFrom(prevLogIndex + 1) {
+			entry := l.toEntry(prevLogIndex + 1 + int32(i))
+			entrySize := proto.Size(entry)
+			if len(entries) > 0 && (len(entries) >= maxBatchEntries || size+entrySize > maxBatchBytes) {
+				break
+			}
+			entries = append(entries, entry)
+			size += entrySize
+		}
+	}
+	return &RPC.AppendEntriesArgs{
+		Term:         rf.currentTerm,
+		LeaderId:     rf.me,
+		PrevLogIndex: prevLogIndex,
+		PrevLogTerm:  prevLogTerm,
+		Entries:      entries,
+		LeaderCommit: rf.commitIndex,
+	}, len(entries)
+}
+
+// 异步发送AppendEntries并处理响应，n为携带的日志条数，-1表示不占用在途窗口的心跳
+func (rf *Raft) sendAppendAsync(idx int, term int32, pr *progress, args *RPC.AppendEntriesArgs, n int) {
+	address := rf.peerAddress(idx)
+	rf.sched.Go(func() {
+		sent := rf.clock.Now()
+		reply, ok := rf.sendAppendEntries(address, args)
+		rf.mu.Lock()
+		defer rf.mu.Unlock()
+		if n > 0 {
+			pr.inflight--
+		}
+		if !rf.progressValid(idx, term, pr) {
+			return
+		}
+		if !ok {
+			// 网络错误，等下一次心跳时从确认过的位置重新探测
+			if n > 0 && pr.state == stateReplicate {
+				pr.becomeProbe()
+				rf.nextIndex[idx] = rf.matchIndex[idx] + 1
+			}
+			return
+		}
+		defer rf.send(pr.notify)
+		if reply.Term > rf.currentTerm { //all server rule 1 If RPC response contains term T > currentTerm:
+			rf.beFollower(reply.Term) // set currentTerm = T, convert to follower (§5.1)
+			return
+		}
+		rf.recordLeaseAck(idx, sent)
+		if reply.Success { //If successful：update nextIndex and matchIndex for follower
+			if match := args.PrevLogIndex + int32(Max(n, 0)); match > rf.matchIndex[idx] {
+				rf.matchIndex[idx] = match
+			}
+			if rf.nextIndex[idx] <= rf.matchIndex[idx] {
+				rf.nextIndex[idx] = rf.matchIndex[idx] + 1
+			}
+			if pr.state == stateProbe && n >= 0 {
+				pr.becomeReplicate()
+			}
+			rf.updateCommitIndex()
+			return
+		}
+		if n < 0 || args.PrevLogIndex < rf.matchIndex[idx] {
+			// 过期的拒绝：follower已经确认过更新的位置
+			return
+		}
+		//If AppendEntries fails because of log inconsistency: decrement nextIndex and retry
+		tarIndex := reply.ConflictIndex //If it does not find an entry with that term
+		if reply.ConflictTerm != NULL {
+			logSize := rf.getLastLogIdx() + 1                 //first search its log for conflictTerm
+			for i := rf.lastIncludedIndex; i < logSize; i++ { //if it finds an entry in its log with that term,
+				if rf.logAt(i).Term != reply.ConflictTerm {
+					continue
+				}
+				for i < logSize && rf.logAt(i).Term == reply.ConflictTerm {
+					i++
+				} //set nextIndex to be the one
+				tarIndex = i //beyond the index of the last entry in that term in its log
+			}
+		}
+		// follower在prevLogIndex处的日志不匹配，下一次至少要从prevLogIndex开始发送。
+		// 乱序到达时follower缺少前面还在途中的日志，这里也会重新发送这部分日志
+		if tarIndex > args.PrevLogIndex {
+			tarIndex = args.PrevLogIndex
+		}
+		if tarIndex <= rf.matchIndex[idx] {
+			tarIndex = rf.matchIndex[idx] + 1
+		}
+		pr.becomeProbe()
+		rf.nextIndex[idx] = tarIndex
+	})
+}
+
+func Max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
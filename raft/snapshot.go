@@ -0,0 +1,195 @@
+package raft
+
+import (
+	"time"
+
+	"hckvstore/util"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"golang.org/x/net/context"
+)
+
+// 持久化状态超过maxRaftState时，丢弃已经apply到状态机的日志前缀。
+// 状态机自己持久化apply过的状态，快照就是状态机在lastApplied时的状态，
+// 只在需要发送给follower时才通过StateMachine.Snapshot生成。
+// 调用者需要持有rf.mu
+func (rf *Raft) maybeSnapshot() {
+	if rf.maxRaftState == -1 || rf.raftStateSize < rf.maxRaftState || rf.lastApplied <= rf.lastIncludedIndex {
+		return
+	}
+	util.DPrintf("[%v] (term %d, state %d) snapshot at index %v, raft state size %v", rf.address, rf.currentTerm, rf.state, rf.lastApplied, rf.raftStateSize)
+	members, learners := rf.configAt(rf.lastApplied)
+	rf.discardLogBefore(rf.lastApplied, rf.logAt(rf.lastApplied).Term)
+	rf.baseMembers = members
+	rf.baseLearners = learnerList(learners)
+	// 状态机需要保证同步写Raft状态之后，之前apply的状态不会丢失
+	rf.persist()
+}
+
+// 丢弃index之前的日志，rf.log[0]变为快照中最后一条日志
+func (rf *Raft) discardLogBefore(index int32, term int32) {
+	var newLog []Log
+	if index <= rf.getLastLogIdx() && rf.logAt(index).Term == term {
+		// 重新分配slice，让被丢弃的日志可以被GC回收
+		newLog = make([]Log, rf.getLastLogIdx()-index+1)
+		copy(newLog, rf.logFrom(index))
+	} else {
+		newLog = make([]Log, 1)
+	}
+	newLog[0] = Log{Term: term}
+	rf.log = newLog
+	rf.lastIncludedIndex = index
+	rf.logChanged(index)
+}
+
+// 把leader当前lastApplied时的快照按chunk发送给follower，成功后更新nextIndex
+func (rf *Raft) sendSnapshot(idx int) bool {
+	rf.mu.Lock()
+	if rf.state != Leader || rf.killed() {
+		rf.mu.Unlock()
+		return false
+	}
+	term := rf.currentTerm
+	address := rf.peerAddress(idx)
+	lastIncludedIndex := rf.lastApplied
+	lastIncludedTerm := rf.logAt(rf.lastApplied).Term
+	members, learners := rf.configAt(rf.lastApplied)
+	// 持有锁时不会有新的日志apply，快照和lastApplied一致
+	data := rf.sm.Snapshot()
+	rf.mu.Unlock()
+
+	util.DPrintf("[%v] (term %d) send snapshot index:%v, %v bytes to server:%v", rf.address, term, lastIncludedIndex, len(data), idx)
+	for offset := 0; ; offset += snapshotChunkSize {
+		end := offset + snapshotChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		args := &RPC.InstallSnapshotArgs{
+			Term:              term,
+			LeaderId:          rf.me,
+			LastIncludedIndex: lastIncludedIndex,
+			LastIncludedTerm:  lastIncludedTerm,
+			Offset:            int64(offset),
+			Data:              data[offset:end],
+			Done:              end == len(data),
+			Members:           members,
+			Learners:          learnerList(learners),
+		}
+		reply, ok := rf.sendInstallSnapshot(address, args)
+		if !ok {
+			return false
+		}
+		rf.mu.Lock()
+		if reply.Term > rf.currentTerm {
+			rf.beFollower(reply.Term)
+			rf.mu.Unlock()
+			return false
+		}
+		if rf.state != Leader || rf.currentTerm != term {
+			rf.mu.Unlock()
+			return false
+		}
+		rf.mu.Unlock()
+		if args.Done {
+			break
+		}
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.state != Leader || rf.currentTerm != term || idx >= len(rf.matchIndex) {
+		return false
+	}
+	if rf.matchIndex[idx] < lastIncludedIndex {
+		rf.matchIndex[idx] = lastIncludedIndex
+	}
+	rf.nextIndex[idx] = rf.matchIndex[idx] + 1
+	rf.updateCommitIndex()
+	return true
+}
+
+func (rf *Raft) sendInstallSnapshot(address string, args *RPC.InstallSnapshotArgs) (*RPC.InstallSnapshotReply, bool) {
+	rf.sleep(time.Millisecond * time.Duration(rf.delay))
+	if !rf.connected() {
+		return nil, false
+	}
+	client, err := rf.transport.Client(address)
+	if err != nil {
+		util.DPrintf("sendInstallSnapshot did not connect: %v %v", err, address)
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	reply, err := client.InstallSnapshot(ctx, args)
+	if err != nil {
+		util.DPrintf("sendInstallSnapshot could not greet: %v %v", err, address)
+		return reply, false
+	}
+	return reply, true
+}
+
+// InstallSnapshot RPC handler.
+func (rf *Raft) InstallSnapshot(ctx context.Context, args *RPC.InstallSnapshotArgs) (*RPC.InstallSnapshotReply, error) {
+	if !rf.connected() {
+		return nil, errDisconnected
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	reply := &RPC.InstallSnapshotReply{Term: rf.currentTerm}
+	//1. Reply immediately if term < currentTerm
+	if args.Term < rf.currentTerm {
+		return reply, nil
+	}
+	if args.Term > rf.currentTerm {
+		rf.beFollower(args.Term)
+		reply.Term = rf.currentTerm
+	}
+	defer rf.send(rf.appendLogCh) //收到当前leader的消息，重置选举超时
+	rf.lastHeartbeat = rf.clock.Now()
+
+	//2. Create new snapshot file if first chunk (offset is 0)
+	if args.Offset == 0 {
+		rf.snapshotBuf = nil
+		rf.snapshotIndex = args.LastIncludedIndex
+	}
+	//3. Write data into snapshot file at given offset
+	if args.LastIncludedIndex != rf.snapshotIndex || args.Offset != int64(len(rf.snapshotBuf)) {
+		// 不属于当前快照或者不连续的chunk直接丢弃，leader会从头重发
+		return reply, nil
+	}
+	rf.snapshotBuf = append(rf.snapshotBuf, args.Data...)
+	//4. Reply and wait for more data chunks if done is false
+	if !args.Done {
+		return reply, nil
+	}
+	data := rf.snapshotBuf
+	rf.snapshotBuf = nil
+
+	//5. Save snapshot file, discard any existing or partial snapshot with a smaller index
+	// 刚加入集群的节点还没有任何配置，即使快照不比本地状态新也要接受
+	newServer := len(rf.baseMembers) == 0 && args.LastIncludedIndex >= rf.lastIncludedIndex
+	if (args.LastIncludedIndex <= rf.lastApplied && !newServer) || args.LastIncludedIndex < rf.lastIncludedIndex || rf.killed() {
+		// 本地状态机已经比快照新
+		return reply, nil
+	}
+	//6. If existing log entry has same index and term as snapshot’s last included entry, retain log entries following it
+	//7. Discard the entire log
+	rf.resolveProposalsInSnapshot(args.LastIncludedIndex, args.LastIncludedTerm)
+	rf.discardLogBefore(args.LastIncludedIndex, args.LastIncludedTerm)
+	rf.baseMembers = args.Members
+	rf.baseLearners = args.Learners
+	rf.reloadConfig()
+	//8. Reset state machine using snapshot contents (and load snapshot’s cluster configuration)
+	// 先恢复状态机再写Raft状态：在两者之间crash时状态机比Raft状态新，重启后leader会
+	// 重新发送快照或者快照之前的日志，最终还是和快照一致；反过来则会丢失快照中的数据
+	rf.sm.Restore(args.LastIncludedIndex, data)
+	rf.persist()
+	if rf.commitIndex < args.LastIncludedIndex {
+		rf.commitIndex = args.LastIncludedIndex
+	}
+	rf.lastApplied = args.LastIncludedIndex
+	util.DPrintf("[%v] (term %d, state %d) install snapshot index:%v from leader:%v", rf.address, rf.currentTerm, rf.state, args.LastIncludedIndex, args.LeaderId)
+	return reply, nil
+}
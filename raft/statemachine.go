@@ -0,0 +1,38 @@
+package raft
+
+// StateMachine是Raft复制的状态机。Raft不解释命令的内容，已提交的日志按下标顺序
+// 通过Apply交给状态机，命令的语义完全由状态机决定。
+//
+// Apply和Restore在持有Raft的锁时被调用，不能再调用Raft的方法。
+// Raft做快照时只丢弃日志前缀，不保存Snapshot的结果：状态机需要自己持久化apply过的状态，
+// 保证Raft下一次同步写入持久化状态之后这些状态不会丢失(例如和Raft共用同一个LevelDB)。
+//
+// 重启之后Raft从lastIncludedIndex开始重新apply之后的日志，其中一部分可能已经apply过。
+// 状态机需要把apply到的日志下标和状态在同一次写入中持久化(例如persister.Batch.SetApplied)，
+// 忽略CommandIndex不大于这个下标的日志：命令不一定幂等，客户端会话也可能已经过期，不能依赖它们去重。
+type StateMachine interface {
+	// Apply applies one committed log entry and returns the command's result,
+	// which is handed to the Proposal of that entry on the leader.
+	Apply(msg ApplyMsg) interface{}
+	// Snapshot returns the state after every entry applied so far. It is
+	// sent to followers whose log is behind the leader's snapshot.
+	Snapshot() []byte
+	// Restore replaces the whole state with a snapshot received from the
+	// leader that includes every entry up to index, and records index as
+	// the last applied entry in the same write.
+	Restore(index int32, snapshot []byte)
+}
+
+// ApplyMsg是交给状态机的一条已提交日志。
+// 空操作和成员变更日志的CommandValid为false，状态机只需要记录下标
+type ApplyMsg struct {
+	CommandValid bool
+	Command      []byte // Start提交的命令
+	CommandIndex int32
+	CommandTerm  int32
+}
+
+// Command是可以编码为日志的命令，例如config.Op
+type Command interface {
+	Encode() []byte
+}
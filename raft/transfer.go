@@ -0,0 +1,150 @@
+package raft
+
+import (
+	"errors"
+	"time"
+
+	"hckvstore/util"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"golang.org/x/net/context"
+)
+
+// 领导权转移(Raft论文§3.10)：leader停止接受新请求，等目标节点的日志追上之后
+// 发送TimeoutNow，目标节点不等选举超时、也不经过PreVote，直接开始选举。
+// 这次选举的RequestVote带有LeadershipTransfer标记，其他成员即使刚收到过leader的
+// 心跳也会处理这次投票请求。
+
+var (
+	ErrTransferInProgress = errors.New("leadership transfer is in progress")
+	ErrTransferTimeout    = errors.New("leadership transfer timed out")
+)
+
+// 转移需要在一个选举超时左右完成，否则放弃转移，恢复接受请求
+const transferTimeout = 2 * minElectionTimeout
+
+// TransferLeadership hands leadership over to target, which must be a
+// member of the current configuration. The leader stops accepting new
+// commands, waits for target to catch up and then tells it to start an
+// election immediately. It blocks until target has won the election or the
+// transfer was aborted.
+func (rf *Raft) TransferLeadership(target string) error {
+	rf.mu.Lock()
+	if rf.state != Leader {
+		rf.mu.Unlock()
+		return ErrNotLeader
+	}
+	if rf.transferTarget != "" {
+		rf.mu.Unlock()
+		return ErrTransferInProgress
+	}
+	if target == rf.address {
+		rf.mu.Unlock()
+		return nil
+	}
+	slot := -1
+	for i, m := range rf.members {
+		if m == target {
+			slot = i
+		}
+	}
+	if slot < 0 {
+		rf.mu.Unlock()
+		return ErrNotMember
+	}
+	if !rf.isVoter(slot) {
+		rf.mu.Unlock()
+		return ErrNotVoter
+	}
+	term := rf.currentTerm
+	rf.transferTarget = target
+	// 目标节点可能在lease到期之前当选，转移期间不能再使用lease读
+	rf.leaseAcks = nil
+	lastLogIdx := rf.getLastLogIdx()
+	rf.mu.Unlock()
+	util.DPrintf("[%v] (term %d) start transferring leadership to %v", rf.address, term, target)
+
+	rf.startAppendLog()
+	if rf.waitMatch(slot, lastLogIdx, term, transferTimeout) {
+		rf.mu.Lock()
+		args := RPC.TimeoutNowArgs{Term: rf.currentTerm, LeaderId: rf.me}
+		rf.mu.Unlock()
+		if ok, reply := rf.sendTimeoutNow(target, &args); ok {
+			rf.mu.Lock()
+			if reply.Term > rf.currentTerm {
+				rf.beFollower(reply.Term)
+			}
+			rf.mu.Unlock()
+		}
+		if rf.waitTermChanged(term, transferTimeout) {
+			return nil
+		}
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.currentTerm != term {
+		// 目标节点(或者其他节点)已经开始了新的任期
+		return nil
+	}
+	util.DPrintf("[%v] (term %d) leadership transfer to %v timed out", rf.address, term, target)
+	if rf.state == Leader {
+		rf.transferTarget = ""
+		rf.leaseAcks = make(map[int32]time.Time)
+	}
+	return ErrTransferTimeout
+}
+
+// 等待任期发生变化，说明已经有节点发起了新的选举
+func (rf *Raft) waitTermChanged(term int32, timeout time.Duration) bool {
+	for start := rf.clock.Now(); rf.clock.Now().Sub(start) < timeout; {
+		rf.mu.Lock()
+		if rf.currentTerm != term {
+			rf.mu.Unlock()
+			return true
+		}
+		rf.mu.Unlock()
+		rf.clock.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+// TimeoutNow RPC handler. 收到当前leader的TimeoutNow之后立即开始选举
+func (rf *Raft) TimeoutNow(ctx context.Context, args *RPC.TimeoutNowArgs) (*RPC.TimeoutNowReply, error) {
+	if !rf.connected() {
+		return nil, errDisconnected
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if args.Term > rf.currentTerm { //all server rule 1 If RPC request or response contains term T > currentTerm:
+		rf.beFollower(args.Term) // set currentTerm = T, convert to follower (§5.1)
+	}
+	reply := &RPC.TimeoutNowReply{Term: rf.currentTerm}
+	if args.Term < rf.currentTerm || !rf.isVoter(int(rf.me)) || rf.state == Leader {
+		return reply, nil
+	}
+	util.DPrintf("[%v] (term %d, state %d) received TimeoutNow from %d", rf.address, rf.currentTerm, rf.state, args.LeaderId)
+	rf.beCandidate(true)
+	rf.send(rf.voteCh) //reset election timer for the new election
+	return reply, nil
+}
+
+func (rf *Raft) sendTimeoutNow(address string, args *RPC.TimeoutNowArgs) (bool, *RPC.TimeoutNowReply) {
+	if !rf.connected() {
+		return false, nil
+	}
+	client, err := rf.transport.Client(address)
+	if err != nil {
+		util.DPrintf("sendTimeoutNow did not connect: %v %v", err, address)
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err := client.TimeoutNow(ctx, args)
+	if err != nil {
+		return false, reply
+	}
+	return true, reply
+}
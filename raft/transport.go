@@ -0,0 +1,90 @@
+package raft
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	RPC "hckvstore/rpc/raftrpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Transport负责Raft节点之间的RPC。Raft只依赖这个接口，
+// 生产环境使用基于gRPC的实现，测试可以使用进程内的模拟网络(见memnet.go)。
+type Transport interface {
+	// Serve把发给address的RPC交给handler处理，阻塞直到Close
+	Serve(address string, handler RPC.RAFTServer)
+	// Client返回发往address的client，连接不可用时RPC返回错误而不是阻塞
+	Client(address string) (RPC.RAFTClient, error)
+	// Retain只保留到keep返回true的地址的连接，其他地址已经不在集群配置中
+	Retain(keep func(address string) bool)
+	// Close停止接收RPC并释放所有连接
+	Close()
+}
+
+// NewGRPCTransport returns the Transport used in production: every node
+// listens on its address with a gRPC server and keeps one long-lived
+// connection per peer.
+func NewGRPCTransport() Transport {
+	return &grpcTransport{}
+}
+
+type grpcTransport struct {
+	mu     sync.Mutex
+	server *grpc.Server // Close时关闭
+	closed bool
+	peers  peerConns // 到其他成员的长连接
+}
+
+func (t *grpcTransport) Serve(address string, handler RPC.RAFTServer) {
+	// Register Server
+	for {
+		lis, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		s := grpc.NewServer()
+		RPC.RegisterRAFTServer(s, handler)
+		// Register reflection service on gRPC server.
+		reflection.Register(s)
+		t.mu.Lock()
+		if t.closed {
+			// Close()可能发生在t.server赋值之前
+			t.mu.Unlock()
+			lis.Close()
+			return
+		}
+		t.server = s
+		t.mu.Unlock()
+		if err := s.Serve(lis); err != nil {
+			fmt.Printf("failed to serve: %v \n", err)
+		}
+		t.mu.Lock()
+		closed := t.closed
+		t.mu.Unlock()
+		if closed {
+			return
+		}
+	}
+}
+
+func (t *grpcTransport) Client(address string) (RPC.RAFTClient, error) {
+	return t.peers.client(address)
+}
+
+func (t *grpcTransport) Retain(keep func(address string) bool) {
+	t.peers.retain(keep)
+}
+
+func (t *grpcTransport) Close() {
+	t.mu.Lock()
+	t.closed = true
+	if t.server != nil {
+		t.server.Stop()
+	}
+	t.mu.Unlock()
+	t.peers.closeAll()
+}
@@ -0,0 +1,886 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0-devel
+// 	protoc        v3.20.0
+// source: ctrler.proto
+
+package ctrlerproto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type JoinArgs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Group   int64    `protobuf:"varint,1,opt,name=Group,proto3" json:"Group,omitempty"`
+	Servers []string `protobuf:"bytes,2,rep,name=Servers,proto3" json:"Servers,omitempty"`
+}
+
+func (x *JoinArgs) Reset() {
+	*x = JoinArgs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JoinArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinArgs) ProtoMessage() {}
+
+func (x *JoinArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinArgs.ProtoReflect.Descriptor instead.
+func (*JoinArgs) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *JoinArgs) GetGroup() int64 {
+	if x != nil {
+		return x.Group
+	}
+	return 0
+}
+
+func (x *JoinArgs) GetServers() []string {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+type LeaveArgs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Group int64 `protobuf:"varint,1,opt,name=Group,proto3" json:"Group,omitempty"`
+}
+
+func (x *LeaveArgs) Reset() {
+	*x = LeaveArgs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeaveArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveArgs) ProtoMessage() {}
+
+func (x *LeaveArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveArgs.ProtoReflect.Descriptor instead.
+func (*LeaveArgs) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LeaveArgs) GetGroup() int64 {
+	if x != nil {
+		return x.Group
+	}
+	return 0
+}
+
+type MoveArgs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Shard int32 `protobuf:"varint,1,opt,name=Shard,proto3" json:"Shard,omitempty"`
+	Group int64 `protobuf:"varint,2,opt,name=Group,proto3" json:"Group,omitempty"`
+}
+
+func (x *MoveArgs) Reset() {
+	*x = MoveArgs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MoveArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveArgs) ProtoMessage() {}
+
+func (x *MoveArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveArgs.ProtoReflect.Descriptor instead.
+func (*MoveArgs) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MoveArgs) GetShard() int32 {
+	if x != nil {
+		return x.Shard
+	}
+	return 0
+}
+
+func (x *MoveArgs) GetGroup() int64 {
+	if x != nil {
+		return x.Group
+	}
+	return 0
+}
+
+// IsLeader为false时client应该换一个节点重试；Success为true时修改已经apply，
+// Join、Leave和Move是幂等的，结果未知时直接重试
+type CtrlerReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IsLeader bool   `protobuf:"varint,1,opt,name=IsLeader,proto3" json:"IsLeader,omitempty"`
+	Success  bool   `protobuf:"varint,2,opt,name=Success,proto3" json:"Success,omitempty"`
+	Err      string `protobuf:"bytes,3,opt,name=Err,proto3" json:"Err,omitempty"`
+	// 修改之后最新的配置编号
+	Num int64 `protobuf:"varint,4,opt,name=Num,proto3" json:"Num,omitempty"`
+}
+
+func (x *CtrlerReply) Reset() {
+	*x = CtrlerReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CtrlerReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CtrlerReply) ProtoMessage() {}
+
+func (x *CtrlerReply) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CtrlerReply.ProtoReflect.Descriptor instead.
+func (*CtrlerReply) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CtrlerReply) GetIsLeader() bool {
+	if x != nil {
+		return x.IsLeader
+	}
+	return false
+}
+
+func (x *CtrlerReply) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CtrlerReply) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+func (x *CtrlerReply) GetNum() int64 {
+	if x != nil {
+		return x.Num
+	}
+	return 0
+}
+
+type QueryArgs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Num int64 `protobuf:"varint,1,opt,name=Num,proto3" json:"Num,omitempty"`
+}
+
+func (x *QueryArgs) Reset() {
+	*x = QueryArgs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryArgs) ProtoMessage() {}
+
+func (x *QueryArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryArgs.ProtoReflect.Descriptor instead.
+func (*QueryArgs) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *QueryArgs) GetNum() int64 {
+	if x != nil {
+		return x.Num
+	}
+	return 0
+}
+
+type Group struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Group   int64    `protobuf:"varint,1,opt,name=Group,proto3" json:"Group,omitempty"`
+	Servers []string `protobuf:"bytes,2,rep,name=Servers,proto3" json:"Servers,omitempty"`
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Group) GetGroup() int64 {
+	if x != nil {
+		return x.Group
+	}
+	return 0
+}
+
+func (x *Group) GetServers() []string {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+type Config struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Num int64 `protobuf:"varint,1,opt,name=Num,proto3" json:"Num,omitempty"`
+	// 第i个元素是shard i所属的组，0表示没有分配
+	Shards []int64  `protobuf:"varint,2,rep,packed,name=Shards,proto3" json:"Shards,omitempty"`
+	Groups []*Group `protobuf:"bytes,3,rep,name=Groups,proto3" json:"Groups,omitempty"`
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Config) GetNum() int64 {
+	if x != nil {
+		return x.Num
+	}
+	return 0
+}
+
+func (x *Config) GetShards() []int64 {
+	if x != nil {
+		return x.Shards
+	}
+	return nil
+}
+
+func (x *Config) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type QueryReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IsLeader bool    `protobuf:"varint,1,opt,name=IsLeader,proto3" json:"IsLeader,omitempty"`
+	Config   *Config `protobuf:"bytes,2,opt,name=Config,proto3" json:"Config,omitempty"`
+}
+
+func (x *QueryReply) Reset() {
+	*x = QueryReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ctrler_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryReply) ProtoMessage() {}
+
+func (x *QueryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrler_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryReply.ProtoReflect.Descriptor instead.
+func (*QueryReply) Descriptor() ([]byte, []int) {
+	return file_ctrler_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *QueryReply) GetIsLeader() bool {
+	if x != nil {
+		return x.IsLeader
+	}
+	return false
+}
+
+func (x *QueryReply) GetConfig() *Config {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+var File_ctrler_proto protoreflect.FileDescriptor
+
+var file_ctrler_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x63, 0x74, 0x72, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3a,
+	0x0a, 0x08, 0x4a, 0x6f, 0x69, 0x6e, 0x41, 0x72, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x12, 0x18, 0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x07, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x22, 0x21, 0x0a, 0x09, 0x4c, 0x65,
+	0x61, 0x76, 0x65, 0x41, 0x72, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x22, 0x36, 0x0a,
+	0x08, 0x4d, 0x6f, 0x76, 0x65, 0x41, 0x72, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x68, 0x61,
+	0x72, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x53, 0x68, 0x61, 0x72, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x22, 0x67, 0x0a, 0x0b, 0x43, 0x74, 0x72, 0x6c, 0x65, 0x72, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x73, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x49, 0x73, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x12, 0x18, 0x0a, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x45, 0x72,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x45, 0x72, 0x72, 0x12, 0x10, 0x0a, 0x03,
+	0x4e, 0x75, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x4e, 0x75, 0x6d, 0x22, 0x1d,
+	0x0a, 0x09, 0x51, 0x75, 0x65, 0x72, 0x79, 0x41, 0x72, 0x67, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x4e,
+	0x75, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x4e, 0x75, 0x6d, 0x22, 0x37, 0x0a,
+	0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x18, 0x0a, 0x07,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x53,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x22, 0x52, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x12, 0x10, 0x0a, 0x03, 0x4e, 0x75, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x4e,
+	0x75, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x03, 0x52, 0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x06, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x52, 0x06, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x49, 0x0a, 0x0a, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x73, 0x4c, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x49, 0x73, 0x4c, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x32, 0x9c, 0x01, 0x0a, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43,
+	0x74, 0x72, 0x6c, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x04, 0x4a, 0x6f, 0x69, 0x6e, 0x12, 0x09, 0x2e,
+	0x4a, 0x6f, 0x69, 0x6e, 0x41, 0x72, 0x67, 0x73, 0x1a, 0x0c, 0x2e, 0x43, 0x74, 0x72, 0x6c, 0x65,
+	0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x23, 0x0a, 0x05, 0x4c, 0x65, 0x61, 0x76,
+	0x65, 0x12, 0x0a, 0x2e, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x41, 0x72, 0x67, 0x73, 0x1a, 0x0c, 0x2e,
+	0x43, 0x74, 0x72, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x21, 0x0a,
+	0x04, 0x4d, 0x6f, 0x76, 0x65, 0x12, 0x09, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x41, 0x72, 0x67, 0x73,
+	0x1a, 0x0c, 0x2e, 0x43, 0x74, 0x72, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x22, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x0a, 0x2e, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x41, 0x72, 0x67, 0x73, 0x1a, 0x0b, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x42, 0x10, 0x5a, 0x0e, 0x2e, 0x2f, 0x3b, 0x63, 0x74, 0x72, 0x6c, 0x65,
+	0x72, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_ctrler_proto_rawDescOnce sync.Once
+	file_ctrler_proto_rawDescData = file_ctrler_proto_rawDesc
+)
+
+func file_ctrler_proto_rawDescGZIP() []byte {
+	file_ctrler_proto_rawDescOnce.Do(func() {
+		file_ctrler_proto_rawDescData = protoimpl.X.CompressGZIP(file_ctrler_proto_rawDescData)
+	})
+	return file_ctrler_proto_rawDescData
+}
+
+var file_ctrler_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_ctrler_proto_goTypes = []interface{}{
+	(*JoinArgs)(nil),    // 0: JoinArgs
+	(*LeaveArgs)(nil),   // 1: LeaveArgs
+	(*MoveArgs)(nil),    // 2: MoveArgs
+	(*CtrlerReply)(nil), // 3: CtrlerReply
+	(*QueryArgs)(nil),   // 4: QueryArgs
+	(*Group)(nil),       // 5: Group
+	(*Config)(nil),      // 6: Config
+	(*QueryReply)(nil),  // 7: QueryReply
+}
+var file_ctrler_proto_depIdxs = []int32{
+	5, // 0: Config.Groups:type_name -> Group
+	6, // 1: QueryReply.Config:type_name -> Config
+	0, // 2: ShardCtrler.Join:input_type -> JoinArgs
+	1, // 3: ShardCtrler.Leave:input_type -> LeaveArgs
+	2, // 4: ShardCtrler.Move:input_type -> MoveArgs
+	4, // 5: ShardCtrler.Query:input_type -> QueryArgs
+	3, // 6: ShardCtrler.Join:output_type -> CtrlerReply
+	3, // 7: ShardCtrler.Leave:output_type -> CtrlerReply
+	3, // 8: ShardCtrler.Move:output_type -> CtrlerReply
+	7, // 9: ShardCtrler.Query:output_type -> QueryReply
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_ctrler_proto_init() }
+func file_ctrler_proto_init() {
+	if File_ctrler_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_ctrler_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JoinArgs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ctrler_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeaveArgs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ctrler_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MoveArgs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ctrler_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CtrlerReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ctrler_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryArgs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ctrler_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Group); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ctrler_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Config); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ctrler_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_ctrler_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ctrler_proto_goTypes,
+		DependencyIndexes: file_ctrler_proto_depIdxs,
+		MessageInfos:      file_ctrler_proto_msgTypes,
+	}.Build()
+	File_ctrler_proto = out.File
+	file_ctrler_proto_rawDesc = nil
+	file_ctrler_proto_goTypes = nil
+	file_ctrler_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ShardCtrlerClient is the client API for ShardCtrler service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ShardCtrlerClient interface {
+	// 加入一个复制组，Servers为这个组的KV服务地址，shard在所有组之间重新均衡
+	Join(ctx context.Context, in *JoinArgs, opts ...grpc.CallOption) (*CtrlerReply, error)
+	// 移除一个复制组，它的shard分给剩下的组
+	Leave(ctx context.Context, in *LeaveArgs, opts ...grpc.CallOption) (*CtrlerReply, error)
+	// 把一个shard分配给指定的组，之后的Join和Leave可能再次移动它
+	Move(ctx context.Context, in *MoveArgs, opts ...grpc.CallOption) (*CtrlerReply, error)
+	// 读取编号为Num的配置，Num为0或者大于最新的编号时返回最新的配置
+	Query(ctx context.Context, in *QueryArgs, opts ...grpc.CallOption) (*QueryReply, error)
+}
+
+type shardCtrlerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShardCtrlerClient(cc grpc.ClientConnInterface) ShardCtrlerClient {
+	return &shardCtrlerClient{cc}
+}
+
+func (c *shardCtrlerClient) Join(ctx context.Context, in *JoinArgs, opts ...grpc.CallOption) (*CtrlerReply, error) {
+	out := new(CtrlerReply)
+	err := c.cc.Invoke(ctx, "/ShardCtrler/Join", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardCtrlerClient) Leave(ctx context.Context, in *LeaveArgs, opts ...grpc.CallOption) (*CtrlerReply, error) {
+	out := new(CtrlerReply)
+	err := c.cc.Invoke(ctx, "/ShardCtrler/Leave", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardCtrlerClient) Move(ctx context.Context, in *MoveArgs, opts ...grpc.CallOption) (*CtrlerReply, error) {
+	out := new(CtrlerReply)
+	err := c.cc.Invoke(ctx, "/ShardCtrler/Move", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardCtrlerClient) Query(ctx context.Context, in *QueryArgs, opts ...grpc.CallOption) (*QueryReply, error) {
+	out := new(QueryReply)
+	err := c.cc.Invoke(ctx, "/ShardCtrler/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShardCtrlerServer is the server API for ShardCtrler service.
+type ShardCtrlerServer interface {
+	// 加入一个复制组，Servers为这个组的KV服务地址，shard在所有组之间重新均衡
+	Join(context.Context, *JoinArgs) (*CtrlerReply, error)
+	// 移除一个复制组，它的shard分给剩下的组
+	Leave(context.Context, *LeaveArgs) (*CtrlerReply, error)
+	// 把一个shard分配给指定的组，之后的Join和Leave可能再次移动它
+	Move(context.Context, *MoveArgs) (*CtrlerReply, error)
+	// 读取编号为Num的配置，Num为0或者大于最新的编号时返回最新的配置
+	Query(context.Context, *QueryArgs) (*QueryReply, error)
+}
+
+// UnimplementedShardCtrlerServer can be embedded to have forward compatible implementations.
+type UnimplementedShardCtrlerServer struct {
+}
+
+func (*UnimplementedShardCtrlerServer) Join(context.Context, *JoinArgs) (*CtrlerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Join not implemented")
+}
+func (*UnimplementedShardCtrlerServer) Leave(context.Context, *LeaveArgs) (*CtrlerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Leave not implemented")
+}
+func (*UnimplementedShardCtrlerServer) Move(context.Context, *MoveArgs) (*CtrlerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Move not implemented")
+}
+func (*UnimplementedShardCtrlerServer) Query(context.Context, *QueryArgs) (*QueryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+
+func RegisterShardCtrlerServer(s *grpc.Server, srv ShardCtrlerServer) {
+	s.RegisterService(&_ShardCtrler_serviceDesc, srv)
+}
+
+func _ShardCtrler_Join_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardCtrlerServer).Join(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ShardCtrler/Join",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardCtrlerServer).Join(ctx, req.(*JoinArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardCtrler_Leave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardCtrlerServer).Leave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ShardCtrler/Leave",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardCtrlerServer).Leave(ctx, req.(*LeaveArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardCtrler_Move_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardCtrlerServer).Move(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ShardCtrler/Move",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardCtrlerServer).Move(ctx, req.(*MoveArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardCtrler_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardCtrlerServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ShardCtrler/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardCtrlerServer).Query(ctx, req.(*QueryArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ShardCtrler_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "ShardCtrler",
+	HandlerType: (*ShardCtrlerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Join",
+			Handler:    _ShardCtrler_Join_Handler,
+		},
+		{
+			MethodName: "Leave",
+			Handler:    _ShardCtrler_Leave_Handler,
+		},
+		{
+			MethodName: "Move",
+			Handler:    _ShardCtrler_Move_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _ShardCtrler_Query_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ctrler.proto",
+}
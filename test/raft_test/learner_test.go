@@ -0,0 +1,80 @@
+package rafttest
+
+import (
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/raft"
+)
+
+// learner接收日志但不参与选举和提交的多数派，追上日志之后可以提升为voter
+func TestLearner(t *testing.T) {
+	c := makeCluster(t, 3, 30900)
+	defer c.cleanup()
+
+	c.put("before", "learner")
+	leader := c.checkOneLeader()
+	var learners []int
+	for _, address := range []string{"127.0.0.1:30903", "127.0.0.1:30904"} {
+		i := c.addNode(address)
+		if err := c.nodes[leader].rf.AddLearner(address); err != nil {
+			t.Fatalf("AddLearner(%v) failed: %v", address, err)
+		}
+		learners = append(learners, i)
+	}
+	c.put("after", "learner")
+	time.Sleep(500 * time.Millisecond)
+	for _, i := range learners {
+		if got := string(c.nodes[i].persister.Get("before")); got != "learner" {
+			t.Fatalf("learner %v has before=%q, want %q", c.addrs[i], got, "learner")
+		}
+	}
+	if err := c.nodes[leader].rf.TransferLeadership(c.addrs[learners[0]]); err != raft.ErrNotVoter {
+		t.Fatalf("TransferLeadership to a learner returned %v, want ErrNotVoter", err)
+	}
+
+	// 只剩一个voter和两个learner时，虽然存活的节点占多数，也不能选出leader
+	var crashed []int
+	for i := 0; i < 3 && len(crashed) < 2; i++ {
+		if i != leader {
+			crashed = append(crashed, i)
+		}
+	}
+	for _, i := range crashed {
+		c.crash(i)
+	}
+	time.Sleep(2 * time.Second)
+	for _, i := range learners {
+		if _, isLeader := c.nodes[i].rf.GetState(); isLeader {
+			t.Fatalf("learner %v became leader", c.addrs[i])
+		}
+	}
+	if idx, _, ok := c.nodes[leader].rf.Start(config.Op{Option: "Put", Key: "lonely", Value: "voter"}); ok {
+		time.Sleep(time.Second)
+		if c.nApplied("lonely", "voter") > 0 {
+			t.Fatalf("entry %v committed without a majority of voters", idx)
+		}
+	}
+	for _, i := range crashed {
+		c.start(i)
+	}
+
+	// 提升一个learner之后它参与投票
+	leader = c.checkOneLeader()
+	c.put("before", "promote")
+	if err := c.nodes[leader].rf.PromoteLearner(c.addrs[learners[0]]); err != nil {
+		t.Fatalf("PromoteLearner failed: %v", err)
+	}
+	if err := c.nodes[leader].rf.PromoteLearner(c.addrs[leader]); err != raft.ErrNotLearner {
+		t.Fatalf("PromoteLearner on a voter returned %v, want ErrNotLearner", err)
+	}
+	c.voters++
+	c.put("after", "promote")
+	if err := c.nodes[leader].rf.TransferLeadership(c.addrs[learners[0]]); err != nil {
+		t.Fatalf("TransferLeadership to the promoted learner failed: %v", err)
+	}
+	if now := c.checkOneLeader(); now != learners[0] {
+		t.Fatalf("expected promoted learner %v to be leader, got %v", c.addrs[learners[0]], c.addrs[now])
+	}
+}
@@ -0,0 +1,32 @@
+package rafttest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaseRead(t *testing.T) {
+	c := makeCluster(t, 3, 30700)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	c.put("key", "v1")
+	if _, ok := c.nodes[leader].rf.LeaseRead(); !ok {
+		t.Fatalf("leader failed to serve LeaseRead")
+	}
+	if got := string(c.nodes[leader].persister.Get("key")); got != "v1" {
+		t.Fatalf("read after LeaseRead got %q, want v1", got)
+	}
+	follower := (leader + 1) % len(c.nodes)
+	if _, ok := c.nodes[follower].rf.LeaseRead(); ok {
+		t.Fatalf("follower served LeaseRead")
+	}
+
+	// 被隔离的leader在lease过期之后不能再直接读本地数据
+	c.nodes[leader].rf.SetConnected(false)
+	time.Sleep(600 * time.Millisecond)
+	if _, ok := c.nodes[leader].rf.LeaseRead(); ok {
+		t.Fatalf("partitioned leader served LeaseRead after its lease expired")
+	}
+	c.nodes[leader].rf.SetConnected(true)
+}
@@ -0,0 +1,60 @@
+package rafttest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAddRemoveServer(t *testing.T) {
+	c := makeCluster(t, 3, 30400)
+	defer c.cleanup()
+
+	for i := 0; i < 10; i++ {
+		c.put(fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i))
+	}
+
+	// 新节点追上leader之后才加入配置
+	leader := c.checkOneLeader()
+	added := c.addNode("127.0.0.1:30403")
+	if err := c.nodes[leader].rf.AddServer(c.addrs[added]); err != nil {
+		t.Fatalf("AddServer failed: %v", err)
+	}
+	c.voters++
+	c.put("after", "add")
+	for i := 0; i < 10; i++ {
+		key, value := fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)
+		if got := string(c.nodes[added].persister.Get(key)); got != value {
+			t.Fatalf("new server has %v=%q, want %q", key, got, value)
+		}
+	}
+
+	// 移除两个初始成员(不是leader)
+	removed := map[int]bool{}
+	for len(removed) < 2 {
+		leader = c.checkOneLeader()
+		victim := 0
+		for victim == leader || victim == added || removed[victim] {
+			victim++
+		}
+		if err := c.nodes[leader].rf.RemoveServer(c.addrs[victim]); err != nil {
+			t.Fatalf("RemoveServer(%v) failed: %v", c.addrs[victim], err)
+		}
+		removed[victim] = true
+		c.voters--
+		c.put(fmt.Sprintf("after-remove-%d", victim), "ok")
+	}
+
+	// 被移除的节点收不到心跳也不会发起选举，剩下的两个成员仍然可以提交日志
+	time.Sleep(2 * time.Second)
+	leader = c.checkOneLeader()
+	if removed[leader] {
+		t.Fatalf("removed server %v became leader", c.addrs[leader])
+	}
+	c.put("final", "done")
+	for i := range removed {
+		if c.nApplied("final", "done") > 2 || string(c.nodes[i].persister.Get("final")) != "" {
+			t.Fatalf("removed server %v still receives logs", c.addrs[i])
+		}
+	}
+}
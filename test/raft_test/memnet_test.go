@@ -0,0 +1,90 @@
+package rafttest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hckvstore/raft"
+)
+
+// 节点通过raft.Network通信，不监听端口
+func makeMemCluster(t *testing.T, n int, seed int64) *cluster {
+	c := &cluster{t: t, voters: n, maxRaftState: -1, network: raft.NewNetwork(seed)}
+	for i := 0; i < n; i++ {
+		c.members = append(c.members, fmt.Sprintf("node-%d", i))
+	}
+	for i := 0; i < n; i++ {
+		c.addNode(c.members[i])
+	}
+	return c
+}
+
+func TestMemNetworkPartition(t *testing.T) {
+	c := makeMemCluster(t, 5, 1)
+	defer c.cleanup()
+
+	c.put("a", "1")
+	leader := c.checkOneLeader()
+	term, _ := c.nodes[leader].rf.GetState()
+
+	// 旧leader和一个follower在少数派，多数派选出新的leader并继续提交
+	minority := []string{c.addrs[leader], c.addrs[(leader+1)%5]}
+	var majority []string
+	for i := 2; i < 5; i++ {
+		majority = append(majority, c.addrs[(leader+i)%5])
+	}
+	c.network.Partition(minority, majority)
+	time.Sleep(time.Second)
+	newLeader := -1
+	for i := 2; i < 5 && newLeader < 0; i++ {
+		for iters := 0; iters < 20; iters++ {
+			if t2, isLeader := c.nodes[(leader+i)%5].rf.GetState(); isLeader && t2 > term {
+				newLeader = (leader + i) % 5
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	if newLeader < 0 {
+		t.Fatalf("majority partition failed to elect a new leader")
+	}
+	c.put("b", "2")
+	if v := string(c.nodes[leader].persister.Get("b")); v != "" {
+		t.Fatalf("old leader in minority applied b=%v", v)
+	}
+
+	c.network.Heal()
+	c.checkOneLeader()
+	c.put("c", "3")
+	time.Sleep(time.Second)
+	if n := c.nApplied("b", "2"); n != 5 {
+		t.Fatalf("b=2 applied on %d servers after heal, want 5", n)
+	}
+}
+
+func TestMemNetworkUnreliable(t *testing.T) {
+	c := makeMemCluster(t, 3, 2)
+	defer c.cleanup()
+
+	c.network.Configure(raft.NetworkConfig{
+		MinDelay:      time.Millisecond,
+		MaxDelay:      10 * time.Millisecond,
+		DropRate:      0.1,
+		DuplicateRate: 0.1,
+		ReorderRate:   0.1,
+	})
+	for i := 0; i < 30; i++ {
+		c.put(fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i))
+	}
+
+	// 网络恢复正常之后所有节点都应该追上
+	c.network.Configure(raft.NetworkConfig{})
+	c.put("final", "done")
+	time.Sleep(time.Second)
+	for i := 0; i < 30; i++ {
+		if n := c.nApplied(fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)); n != 3 {
+			t.Fatalf("key-%d applied on %d servers, want 3", i, n)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package rafttest
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+	pst "hckvstore/persister"
+	"hckvstore/raft"
+)
+
+type node struct {
+	rf        *raft.Raft
+	persister *pst.Persister
+}
+
+type cluster struct {
+	t            *testing.T
+	members      []string // 初始成员
+	addrs        []string // 所有节点的地址，包括之后加入的节点
+	dirs         []string
+	nodes        []*node
+	voters       int // 当前配置中的成员数量，put需要多数成员apply
+	maxRaftState int
+	network      *raft.Network // 不为nil时节点通过进程内的模拟网络通信
+}
+
+func makeCluster(t *testing.T, n int, basePort int) *cluster {
+	return makeClusterSnapshot(t, n, basePort, -1)
+}
+
+func makeClusterSnapshot(t *testing.T, n int, basePort int, maxRaftState int) *cluster {
+	c := &cluster{t: t, voters: n, maxRaftState: maxRaftState}
+	for i := 0; i < n; i++ {
+		c.members = append(c.members, "127.0.0.1:"+strconv.Itoa(basePort+i))
+	}
+	for i := 0; i < n; i++ {
+		c.addNode(c.members[i])
+	}
+	return c
+}
+
+// 启动一个新节点，不在初始成员中的地址会以空配置启动
+func (c *cluster) addNode(address string) int {
+	c.addrs = append(c.addrs, address)
+	c.dirs = append(c.dirs, c.t.TempDir())
+	c.nodes = append(c.nodes, nil)
+	c.start(len(c.nodes) - 1)
+	return len(c.nodes) - 1
+}
+
+// 启动(或重启)第i个节点，复用它之前的LevelDB目录
+func (c *cluster) start(i int) {
+	nd := &node{persister: &pst.Persister{}}
+	nd.persister.Init(c.dirs[i])
+	sm := store.MakeStore(nd.persister, store.DefaultSessionTTL)
+	if c.network != nil {
+		nd.rf = raft.MakeRaftWithTransport(c.addrs[i], c.members, nd.persister, &sync.Mutex{}, sm, 0, c.maxRaftState, 100*time.Millisecond, c.network.Transport(c.addrs[i]))
+	} else {
+		nd.rf = raft.MakeRaft(c.addrs[i], c.members, nd.persister, &sync.Mutex{}, sm, 0, c.maxRaftState, 100*time.Millisecond)
+	}
+	c.nodes[i] = nd
+}
+
+func (c *cluster) crash(i int) {
+	c.nodes[i].rf.Kill()
+	c.nodes[i].persister.Close()
+	c.nodes[i] = nil
+}
+
+func (c *cluster) cleanup() {
+	for i := range c.nodes {
+		if c.nodes[i] != nil {
+			c.crash(i)
+		}
+	}
+}
+
+// 等待选出唯一的leader
+func (c *cluster) checkOneLeader() int {
+	for iters := 0; iters < 20; iters++ {
+		time.Sleep(500 * time.Millisecond)
+		leaders := make(map[int32][]int)
+		var lastTerm int32 = -1
+		for i, nd := range c.nodes {
+			if nd == nil {
+				continue
+			}
+			if term, isLeader := nd.rf.GetState(); isLeader {
+				leaders[term] = append(leaders[term], i)
+				if term > lastTerm {
+					lastTerm = term
+				}
+			}
+		}
+		for term, ls := range leaders {
+			if len(ls) > 1 {
+				c.t.Fatalf("term %d has %d (>1) leaders", term, len(ls))
+			}
+		}
+		if lastTerm != -1 {
+			return leaders[lastTerm][0]
+		}
+	}
+	c.t.Fatalf("expected one leader, got none")
+	return -1
+}
+
+// 提交一条Put日志，直到多数节点的LevelDB中都能读到这个值
+func (c *cluster) put(key string, value string) {
+	op := config.Op{Option: "Put", Key: key, Value: value}
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, nd := range c.nodes {
+			if nd == nil {
+				continue
+			}
+			if _, _, isLeader := nd.rf.Start(op); !isLeader {
+				continue
+			}
+			for t := time.Now(); time.Since(t) < 2*time.Second; {
+				if c.nApplied(key, value) > c.voters/2 {
+					return
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	c.t.Fatalf("put %v=%v failed to reach agreement", key, value)
+}
+
+// 等待第i个节点apply key=value，put只保证多数节点已经apply
+func (c *cluster) waitApplied(i int, key string, value string) bool {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(20 * time.Millisecond) {
+		if string(c.nodes[i].persister.Get(key)) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cluster) nApplied(key string, value string) int {
+	n := 0
+	for _, nd := range c.nodes {
+		if nd != nil && string(nd.persister.Get(key)) == value {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPersistTerm(t *testing.T) {
+	c := makeCluster(t, 3, 30100)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	term, _ := c.nodes[leader].rf.GetState()
+	for i := range c.nodes {
+		c.crash(i)
+	}
+	for i := range c.nodes {
+		c.start(i)
+	}
+	// 重启后的term不能回退
+	for i, nd := range c.nodes {
+		if t2, _ := nd.rf.GetState(); t2 < term {
+			t.Fatalf("server %d restarted at term %d, before crash it was %d", i, t2, term)
+		}
+	}
+	c.checkOneLeader()
+}
+
+func TestPersistCrashDuringWorkload(t *testing.T) {
+	c := makeCluster(t, 3, 30200)
+	defer c.cleanup()
+
+	committed := make(map[string]string)
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 5; i++ {
+			key := fmt.Sprintf("key-%d-%d", round, i)
+			value := fmt.Sprintf("value-%d-%d", round, i)
+			c.put(key, value)
+			committed[key] = value
+		}
+		// 轮流crash leader和follower，然后重启
+		victim := c.checkOneLeader()
+		if round%2 == 1 {
+			victim = (victim + 1) % len(c.nodes)
+		}
+		c.crash(victim)
+		c.put(fmt.Sprintf("key-%d-down", round), "down")
+		committed[fmt.Sprintf("key-%d-down", round)] = "down"
+		c.start(victim)
+	}
+
+	// 所有节点重启后，已提交的写都不能丢失
+	for i := range c.nodes {
+		c.crash(i)
+	}
+	for i := range c.nodes {
+		c.start(i)
+	}
+	c.put("final", "done")
+	for key, value := range committed {
+		if n := c.nApplied(key, value); n <= len(c.nodes)/2 {
+			t.Fatalf("committed write %v=%v lost, only on %d servers", key, value, n)
+		}
+	}
+}
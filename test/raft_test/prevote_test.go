@@ -0,0 +1,76 @@
+package rafttest
+
+import (
+	"testing"
+	"time"
+)
+
+// 被隔离的follower在PreVote阶段得不到多数同意，不会增加term，
+// 重新连通之后leader保持不变
+func TestPreVoteIsolatedFollowerRejoins(t *testing.T) {
+	c := makeCluster(t, 3, 30500)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	c.put("before", "partition")
+	term, _ := c.nodes[leader].rf.GetState()
+
+	follower := (leader + 1) % len(c.nodes)
+	c.nodes[follower].rf.SetConnected(false)
+	// 隔离期间经过多个选举超时
+	time.Sleep(3 * time.Second)
+	if t2, _ := c.nodes[follower].rf.GetState(); t2 != term {
+		t.Fatalf("isolated follower bumped its term from %d to %d", term, t2)
+	}
+	c.put("during", "partition")
+
+	c.nodes[follower].rf.SetConnected(true)
+	time.Sleep(2 * time.Second)
+	if now := c.checkOneLeader(); now != leader {
+		t.Fatalf("leader changed from %v to %v after the follower rejoined", c.addrs[leader], c.addrs[now])
+	}
+	if t2, _ := c.nodes[leader].rf.GetState(); t2 != term {
+		t.Fatalf("leader term changed from %d to %d after the follower rejoined", term, t2)
+	}
+	c.put("after", "partition")
+	if got := string(c.nodes[follower].persister.Get("during")); got != "partition" {
+		t.Fatalf("rejoined follower missed committed write, got %q", got)
+	}
+}
+
+// 被隔离的leader重新连通后，它的旧term不会打断新leader
+func TestPreVoteIsolatedLeaderRejoins(t *testing.T) {
+	c := makeCluster(t, 3, 30510)
+	defer c.cleanup()
+
+	old := c.checkOneLeader()
+	c.nodes[old].rf.SetConnected(false)
+	time.Sleep(2 * time.Second)
+
+	var leader int
+	for iters := 0; iters < 20; iters++ {
+		leader = -1
+		for i, nd := range c.nodes {
+			if _, isLeader := nd.rf.GetState(); isLeader && i != old {
+				leader = i
+			}
+		}
+		if leader != -1 {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if leader == -1 {
+		t.Fatalf("majority failed to elect a new leader")
+	}
+	term, _ := c.nodes[leader].rf.GetState()
+
+	c.nodes[old].rf.SetConnected(true)
+	time.Sleep(2 * time.Second)
+	if now := c.checkOneLeader(); now != leader {
+		t.Fatalf("leader changed from %v to %v after the old leader rejoined", c.addrs[leader], c.addrs[now])
+	}
+	if t2, _ := c.nodes[leader].rf.GetState(); t2 != term {
+		t.Fatalf("leader term changed from %d to %d after the old leader rejoined", term, t2)
+	}
+}
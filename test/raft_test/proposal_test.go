@@ -0,0 +1,72 @@
+package rafttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/raft"
+)
+
+func TestProposalApplied(t *testing.T) {
+	c := makeMemCluster(t, 3, 4)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	p, err := c.nodes[leader].rf.Propose(config.Op{Option: "Put", Key: "k", Value: "v"})
+	if err != nil {
+		t.Fatalf("Propose on leader: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Wait(ctx); err != nil {
+		t.Fatalf("proposal at %d: %v", p.Index, err)
+	}
+	// Wait返回时状态机已经apply了这条日志
+	if got := string(c.nodes[leader].persister.Get("k")); got != "v" {
+		t.Fatalf("leader has k=%q after the proposal was applied", got)
+	}
+	if _, err := c.nodes[(leader+1)%3].rf.Propose(config.Op{Option: "Put", Key: "k", Value: "v"}); err != raft.ErrNotLeader {
+		t.Fatalf("Propose on follower returned %v, want ErrNotLeader", err)
+	}
+}
+
+// 被隔离的leader上的提案不会被提交：等待超时返回ctx的错误，分区恢复之后日志被新leader覆盖，返回ErrProposalDropped
+func TestProposalDropped(t *testing.T) {
+	c := makeMemCluster(t, 3, 5)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	c.put("a", "1")
+	var others []string
+	for i, addr := range c.addrs {
+		if i != leader {
+			others = append(others, addr)
+		}
+	}
+	c.network.Partition([]string{c.addrs[leader]}, others)
+	p, err := c.nodes[leader].rf.Propose(config.Op{Option: "Put", Key: "lost", Value: "x"})
+	if err != nil {
+		t.Fatalf("Propose on isolated leader: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := p.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("proposal on isolated leader returned %v, want DeadlineExceeded", err)
+	}
+
+	// 多数派选出新leader并在同一个下标提交其他日志
+	time.Sleep(2 * time.Second)
+	c.put("b", "2")
+	c.put("c", "3")
+	c.network.Heal()
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel2()
+	if err := p.Wait(ctx2); err != raft.ErrProposalDropped {
+		t.Fatalf("overwritten proposal returned %v, want ErrProposalDropped", err)
+	}
+	if n := c.nApplied("lost", "x"); n != 0 {
+		t.Fatalf("dropped proposal applied on %d servers", n)
+	}
+}
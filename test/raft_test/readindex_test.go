@@ -0,0 +1,43 @@
+package rafttest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadIndex(t *testing.T) {
+	c := makeCluster(t, 3, 30600)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	c.put("key", "v1")
+	if _, ok := c.nodes[leader].rf.ReadIndex(); !ok {
+		t.Fatalf("leader failed to serve ReadIndex")
+	}
+	if got := string(c.nodes[leader].persister.Get("key")); got != "v1" {
+		t.Fatalf("read after ReadIndex got %q, want v1", got)
+	}
+	follower := (leader + 1) % len(c.nodes)
+	if _, ok := c.nodes[follower].rf.ReadIndex(); ok {
+		t.Fatalf("follower served ReadIndex")
+	}
+}
+
+// 被隔离的旧leader无法通过心跳确认自己的身份，不能返回过期的数据
+func TestReadIndexPartitionedLeader(t *testing.T) {
+	c := makeCluster(t, 3, 30610)
+	defer c.cleanup()
+
+	old := c.checkOneLeader()
+	c.put("key", "v1")
+	c.nodes[old].rf.SetConnected(false)
+
+	// 多数派选出新leader并写入新值
+	time.Sleep(2 * time.Second)
+	c.put("key", "v2")
+
+	if _, ok := c.nodes[old].rf.ReadIndex(); ok {
+		t.Fatalf("partitioned leader served a read, value %q may be stale", c.nodes[old].persister.Get("key"))
+	}
+	c.nodes[old].rf.SetConnected(true)
+}
@@ -0,0 +1,53 @@
+package rafttest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 并发提交大量日志，落后的follower重启之后通过分批的AppendEntries追上leader
+func TestPipelinedReplication(t *testing.T) {
+	c := makeCluster(t, 3, 31000)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	follower := (leader + 1) % len(c.nodes)
+	c.crash(follower)
+
+	const clients, puts = 10, 30
+	var wg sync.WaitGroup
+	for g := 0; g < clients; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < puts; i++ {
+				c.put(fmt.Sprintf("key-%d-%d", g, i), fmt.Sprintf("value-%d-%d", g, i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	c.start(follower)
+	c.put("after", "restart")
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		missing := ""
+		for g := 0; g < clients && missing == ""; g++ {
+			for i := 0; i < puts; i++ {
+				key, value := fmt.Sprintf("key-%d-%d", g, i), fmt.Sprintf("value-%d-%d", g, i)
+				if string(c.nodes[follower].persister.Get(key)) != value {
+					missing = key
+					break
+				}
+			}
+		}
+		if missing == "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("restarted follower is missing %v", missing)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
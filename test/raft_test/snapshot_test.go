@@ -0,0 +1,68 @@
+package rafttest
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSnapshotInstall(t *testing.T) {
+	c := makeClusterSnapshot(t, 3, 30300, 1000)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	c.put("before", "crash")
+	// follower下线期间leader会做快照丢弃日志，重启后只能通过InstallSnapshot追上
+	follower := (leader + 1) % len(c.nodes)
+	c.crash(follower)
+	for i := 0; i < 40; i++ {
+		c.put(fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i))
+	}
+	c.start(follower)
+	c.put("after", "restart")
+	for i := 0; i < 40; i++ {
+		key, value := fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)
+		if !c.waitApplied(follower, key, value) {
+			t.Fatalf("restarted follower has %v=%q, want %q", key, c.nodes[follower].persister.Get(key), value)
+		}
+	}
+
+	// 所有节点从快照和剩余的日志恢复
+	for i := range c.nodes {
+		c.crash(i)
+	}
+	for i := range c.nodes {
+		c.start(i)
+	}
+	c.put("final", "done")
+	for i := 0; i < 40; i++ {
+		key, value := fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)
+		if n := c.nApplied(key, value); n <= len(c.nodes)/2 {
+			t.Fatalf("committed write %v=%v lost, only on %d servers", key, value, n)
+		}
+	}
+}
+
+// 每条日志保存在各自的key下：做快照之后被丢弃的日志从LevelDB中删除，剩下的日志连续
+func TestSnapshotDeletesStoredLog(t *testing.T) {
+	c := makeClusterSnapshot(t, 3, 30310, 1000)
+	defer c.cleanup()
+
+	c.checkOneLeader()
+	for i := 0; i < 60; i++ {
+		c.put(fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i))
+	}
+	for i, nd := range c.nodes {
+		var indexes []int64
+		nd.persister.ReadRaftLog(func(index int64, data []byte) {
+			indexes = append(indexes, index)
+		})
+		if len(indexes) == 0 || indexes[0] == 0 || len(indexes) >= 60 {
+			t.Fatalf("server %d stores log entries %v after snapshots", i, indexes)
+		}
+		for j := 1; j < len(indexes); j++ {
+			if indexes[j] != indexes[j-1]+1 {
+				t.Fatalf("server %d stores log entries %v with a gap", i, indexes)
+			}
+		}
+	}
+}
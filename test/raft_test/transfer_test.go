@@ -0,0 +1,85 @@
+package rafttest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/raft"
+	RPC "hckvstore/rpc/raftrpc"
+)
+
+// 领导权转移之后目标节点成为leader，已提交的数据不丢失
+func TestTransferLeadership(t *testing.T) {
+	c := makeCluster(t, 3, 30800)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	c.put("before", "transfer")
+	term, _ := c.nodes[leader].rf.GetState()
+
+	target := (leader + 1) % len(c.nodes)
+	if err := c.nodes[leader].rf.TransferLeadership(c.addrs[target]); err != nil {
+		t.Fatalf("TransferLeadership failed: %v", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	if now := c.checkOneLeader(); now != target {
+		t.Fatalf("expected %v to be leader after the transfer, got %v", c.addrs[target], c.addrs[now])
+	}
+	if t2, _ := c.nodes[target].rf.GetState(); t2 <= term {
+		t.Fatalf("new leader term %d should be larger than %d", t2, term)
+	}
+	c.put("after", "transfer")
+	if got := string(c.nodes[leader].persister.Get("before")); got != "transfer" {
+		t.Fatalf("old leader lost committed write, got %q", got)
+	}
+}
+
+// 目标节点无法追上日志时转移超时，原leader恢复接受请求
+func TestTransferLeadershipTimeout(t *testing.T) {
+	c := makeCluster(t, 3, 30810)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	target := (leader + 1) % len(c.nodes)
+	c.nodes[target].rf.SetConnected(false)
+
+	errCh := make(chan error)
+	go func() { errCh <- c.nodes[leader].rf.TransferLeadership(c.addrs[target]) }()
+	time.Sleep(100 * time.Millisecond)
+	if _, _, ok := c.nodes[leader].rf.Start(config.Op{Option: "Put", Key: "k", Value: "v"}); ok {
+		t.Fatalf("leader accepted a command during leadership transfer")
+	}
+	if err := <-errCh; err != raft.ErrTransferTimeout {
+		t.Fatalf("expected ErrTransferTimeout, got %v", err)
+	}
+	if now := c.checkOneLeader(); now != leader {
+		t.Fatalf("leader changed from %v to %v after a failed transfer", c.addrs[leader], c.addrs[now])
+	}
+	c.nodes[target].rf.SetConnected(true)
+	c.put("after", "timeout")
+}
+
+// TimeoutNow的任期比自己的大时先转为这个任期的follower，新的选举使用更大的任期，重启之后任期不回退
+func TestTimeoutNowHigherTerm(t *testing.T) {
+	c := makeCluster(t, 3, 30820)
+	defer c.cleanup()
+
+	leader := c.checkOneLeader()
+	target := (leader + 1) % len(c.nodes)
+	term, _ := c.nodes[target].rf.GetState()
+	args := &RPC.TimeoutNowArgs{Term: term + 5, LeaderId: int32(leader)}
+	if _, err := c.nodes[target].rf.TimeoutNow(context.Background(), args); err != nil {
+		t.Fatalf("TimeoutNow failed: %v", err)
+	}
+	if t2, _ := c.nodes[target].rf.GetState(); t2 <= args.Term {
+		t.Fatalf("election started at term %d, TimeoutNow was sent at term %d", t2, args.Term)
+	}
+	c.crash(target)
+	c.start(target)
+	if t2, _ := c.nodes[target].rf.GetState(); t2 <= args.Term {
+		t.Fatalf("restarted at term %d, before crash it was larger than %d", t2, args.Term)
+	}
+	c.checkOneLeader()
+}
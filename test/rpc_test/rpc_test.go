@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"hckvstore/config"
 	"log"
+	"reflect"
 	"testing"
 )
 
@@ -18,3 +19,32 @@ func TestJson(t *testing.T) {
 	json.Unmarshal(data, op2)
 	log.Println(op2)
 }
+
+func TestOpEncode(t *testing.T) {
+	op := config.Op{Option: "Put", Key: "1", Value: "v", Id: 7, Seq: 3}
+	op2, err := config.DecodeOp(op.Encode())
+	if err != nil {
+		t.Fatalf("DecodeOp failed: %v", err)
+	}
+	if !reflect.DeepEqual(op2, op) {
+		t.Fatalf("decoded %+v, want %+v", op2, op)
+	}
+}
+
+func TestTxnEncode(t *testing.T) {
+	op := config.Op{
+		Option:   "Txn",
+		Id:       7,
+		Seq:      4,
+		Compares: []config.Compare{{Key: "a", Target: "Version", Result: "=", Version: 12}},
+		Success:  []config.Op{{Option: "Put", Key: "a", Value: "1"}, {Option: "Delete", Key: "b"}},
+		Failure:  []config.Op{{Option: "Get", Key: "a"}},
+	}
+	op2, err := config.DecodeOp(op.Encode())
+	if err != nil {
+		t.Fatalf("DecodeOp failed: %v", err)
+	}
+	if !reflect.DeepEqual(op2, op) {
+		t.Fatalf("decoded %+v, want %+v", op2, op)
+	}
+}
@@ -0,0 +1,192 @@
+package shardctrlertest
+
+import (
+	"testing"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	pst "hckvstore/persister"
+	"hckvstore/raft"
+)
+
+type fixture struct {
+	t         *testing.T
+	persister *pst.Persister
+	c         *shardctrler.Ctrler
+	index     int32
+}
+
+func makeCtrler(t *testing.T) *fixture {
+	p := &pst.Persister{}
+	p.Init(t.TempDir())
+	t.Cleanup(p.Close)
+	return &fixture{t: t, persister: p, c: shardctrler.MakeCtrler(p)}
+}
+
+func (f *fixture) apply(op config.Op) shardctrler.Reply {
+	f.index++
+	result := f.c.Apply(raft.ApplyMsg{CommandValid: true, Command: op.Encode(), CommandIndex: f.index, CommandTerm: 1})
+	reply, ok := result.(shardctrler.Reply)
+	if !ok {
+		f.t.Fatalf("Apply(%+v) returned %T, want shardctrler.Reply", op, result)
+	}
+	return reply
+}
+
+func (f *fixture) join(gid int64) shardctrler.Reply {
+	return f.apply(config.Op{Option: shardctrler.OpJoin, Group: gid, Servers: []string{"server-" + string(rune('a'+gid))}})
+}
+
+// 所有shard都分配给了配置中的组，并且每个组负责的shard数量最多相差1
+func checkBalanced(t *testing.T, cfg shardctrler.Config) {
+	count := make(map[int64]int)
+	for shard, gid := range cfg.Shards {
+		if _, ok := cfg.Groups[gid]; !ok {
+			t.Fatalf("config %d assigns shard %d to group %d, which is not in %v", cfg.Num, shard, gid, cfg.Groups)
+		}
+		count[gid]++
+	}
+	min, max := shardctrler.NShards, 0
+	for gid := range cfg.Groups {
+		if count[gid] < min {
+			min = count[gid]
+		}
+		if count[gid] > max {
+			max = count[gid]
+		}
+	}
+	if max-min > 1 {
+		t.Fatalf("config %d is unbalanced: %v", cfg.Num, cfg.Shards)
+	}
+}
+
+// 返回从a到b移动的shard数量
+func moved(a, b shardctrler.Config) int {
+	n := 0
+	for i := range a.Shards {
+		if a.Shards[i] != b.Shards[i] {
+			n++
+		}
+	}
+	return n
+}
+
+func TestJoinLeave(t *testing.T) {
+	f := makeCtrler(t)
+	if cfg := f.c.Query(0); cfg.Num != 0 || cfg.Shards != [shardctrler.NShards]int64{} {
+		t.Fatalf("initial config is %+v", cfg)
+	}
+	for gid := int64(1); gid <= 3; gid++ {
+		if reply := f.join(gid); reply.Err != "" || reply.Num != gid {
+			t.Fatalf("join %d returned %+v", gid, reply)
+		}
+		checkBalanced(t, f.c.Query(0))
+	}
+	// 第三个组加入时只从前两个组各移出必要的shard
+	if n := moved(f.c.Query(2), f.c.Query(3)); n != 3 {
+		t.Fatalf("join of the third group moved %d shards, want 3", n)
+	}
+	// 重复的Join不生成新配置
+	if reply := f.join(3); reply.Num != 3 {
+		t.Fatalf("repeated join created config %d", reply.Num)
+	}
+
+	before := f.c.Query(0)
+	if reply := f.apply(config.Op{Option: shardctrler.OpLeave, Group: 2}); reply.Num != 4 {
+		t.Fatalf("leave returned %+v", reply)
+	}
+	after := f.c.Query(0)
+	checkBalanced(t, after)
+	for shard, gid := range before.Shards {
+		if gid != 2 && after.Shards[shard] != gid {
+			t.Fatalf("leave of group 2 moved shard %d from group %d", shard, gid)
+		}
+	}
+	if reply := f.apply(config.Op{Option: shardctrler.OpLeave, Group: 2}); reply.Num != 4 {
+		t.Fatalf("repeated leave created config %d", reply.Num)
+	}
+
+	// 旧的配置仍然可以读
+	if cfg := f.c.Query(1); cfg.Num != 1 || len(cfg.Groups) != 1 {
+		t.Fatalf("config 1 is %+v", cfg)
+	}
+}
+
+func TestMove(t *testing.T) {
+	f := makeCtrler(t)
+	f.join(1)
+	f.join(2)
+	if reply := f.apply(config.Op{Option: shardctrler.OpMove, Shard: 0, Group: 3}); reply.Err != shardctrler.ErrGroupNotJoined {
+		t.Fatalf("move to a group that has not joined returned %+v", reply)
+	}
+	if reply := f.apply(config.Op{Option: shardctrler.OpMove, Shard: shardctrler.NShards, Group: 1}); reply.Err != shardctrler.ErrInvalidShard {
+		t.Fatalf("move of an invalid shard returned %+v", reply)
+	}
+	target := int64(1)
+	if f.c.Query(0).Shards[0] == 1 {
+		target = 2
+	}
+	reply := f.apply(config.Op{Option: shardctrler.OpMove, Shard: 0, Group: target})
+	if cfg := f.c.Query(0); reply.Err != "" || cfg.Num != 3 || cfg.Shards[0] != target {
+		t.Fatalf("move returned %+v, config %+v", reply, cfg)
+	}
+}
+
+// 快照包含所有配置
+func TestCtrlerRestore(t *testing.T) {
+	f := makeCtrler(t)
+	f.join(1)
+	f.join(2)
+	g := makeCtrler(t)
+	g.c.Restore(f.index, f.c.Snapshot())
+	for num := int64(0); num <= 2; num++ {
+		a, b := f.c.Query(num), g.c.Query(num)
+		if a.Num != b.Num || a.Shards != b.Shards || len(a.Groups) != len(b.Groups) {
+			t.Fatalf("restored config %d is %+v, want %+v", num, b, a)
+		}
+	}
+}
+
+// 重启之后Raft从快照的位置重新apply日志，重放Join(1)、Join(2)、Leave(2)不能生成新配置
+func TestCtrlerReplay(t *testing.T) {
+	f := makeCtrler(t)
+	ops := []config.Op{
+		{Option: shardctrler.OpJoin, Group: 1, Servers: []string{"server-b"}},
+		{Option: shardctrler.OpJoin, Group: 2, Servers: []string{"server-c"}},
+		{Option: shardctrler.OpLeave, Group: 2},
+	}
+	for _, op := range ops {
+		f.apply(op)
+	}
+	c := shardctrler.MakeCtrler(f.persister)
+	for i, op := range ops {
+		if result := c.Apply(raft.ApplyMsg{CommandValid: true, Command: op.Encode(), CommandIndex: int32(i + 1), CommandTerm: 1}); result != nil {
+			t.Fatalf("replayed entry %d returned %+v", i+1, result)
+		}
+	}
+	if cfg := c.Query(0); cfg.Num != 3 {
+		t.Fatalf("latest config after the replay is %d, want 3", cfg.Num)
+	}
+
+	// 从快照恢复的状态机同样跳过快照中已经包含的日志
+	g := makeCtrler(t)
+	g.c.Restore(f.index, f.c.Snapshot())
+	if result := g.c.Apply(raft.ApplyMsg{CommandValid: true, Command: ops[2].Encode(), CommandIndex: f.index, CommandTerm: 1}); result != nil {
+		t.Fatalf("entry in the snapshot returned %+v", result)
+	}
+}
+
+func TestKey2Shard(t *testing.T) {
+	seen := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		key := string(rune('a'+i%26)) + string(rune('a'+i/26))
+		shard := shardctrler.Key2Shard(key)
+		if shard < 0 || shard >= shardctrler.NShards || shard != shardctrler.Key2Shard(key) {
+			t.Fatalf("Key2Shard(%q) = %d", key, shard)
+		}
+		seen[shard] = true
+	}
+	if len(seen) != shardctrler.NShards {
+		t.Fatalf("keys only map to %d shards", len(seen))
+	}
+}
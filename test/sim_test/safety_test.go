@@ -0,0 +1,150 @@
+package simtest
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// 默认运行seed 1..n。SIM_SEED指定时只运行这个seed，用来重放失败的运行；
+// SIM_SEEDS可以增加运行的seed数量
+func seeds(t *testing.T, n int) []int64 {
+	if v := os.Getenv("SIM_SEED"); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			t.Fatalf("bad SIM_SEED %q: %v", v, err)
+		}
+		return []int64{seed}
+	}
+	if v := os.Getenv("SIM_SEEDS"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil {
+			t.Fatalf("bad SIM_SEEDS %q: %v", v, err)
+		}
+	}
+	var seeds []int64
+	for i := 1; i <= n; i++ {
+		seeds = append(seeds, int64(i))
+	}
+	return seeds
+}
+
+// 随机提交请求、crash和重启节点、制造和恢复网络分区，每一步都检查安全性
+func (s *simulator) workload(d time.Duration) {
+	down := func() []int {
+		var ids []int
+		for i, nd := range s.nodes {
+			if nd == nil {
+				ids = append(ids, i)
+			}
+		}
+		return ids
+	}
+	for end, n := s.now.Add(d), 0; s.now.Before(end); n++ {
+		s.run(50 * time.Millisecond)
+		switch r := s.rand.Intn(100); {
+		case r < 50:
+			s.start1(fmt.Sprintf("key-%d", n), fmt.Sprintf("value-%d", n))
+		case r < 54:
+			// 最多同时crash少数节点
+			if len(down()) < (len(s.nodes)-1)/2 {
+				if i := s.rand.Intn(len(s.nodes)); s.nodes[i] != nil {
+					s.crash(i)
+				}
+			}
+		case r < 60:
+			if ids := down(); len(ids) > 0 {
+				s.start(ids[s.rand.Intn(len(ids))])
+			}
+		case r < 63:
+			perm := s.rand.Perm(len(s.nodes))
+			k := 1 + s.rand.Intn(len(s.nodes)-1)
+			s.setPartition(perm[:k], perm[k:])
+		case r < 70:
+			s.heal()
+		}
+	}
+}
+
+// 网络恢复、所有节点重启之后，新的请求能被所有节点提交
+func (s *simulator) checkRecovered() {
+	s.heal()
+	for i, nd := range s.nodes {
+		if nd == nil {
+			s.start(i)
+		}
+	}
+	for start := s.now; s.now.Sub(start) < 10*time.Second; {
+		if s.start1("final", "done") {
+			break
+		}
+		s.run(100 * time.Millisecond)
+	}
+	s.run(5 * time.Second)
+	var last int32 = -1
+	for i, nd := range s.nodes {
+		st := nd.rf.LogState()
+		if last >= 0 && st.CommitIndex != last {
+			s.fatalf("%v committed up to %d, others up to %d", s.addrs[i], st.CommitIndex, last)
+		}
+		last = st.CommitIndex
+	}
+	if last <= 0 {
+		s.fatalf("nothing committed after recovery")
+	}
+}
+
+func TestSimElection(t *testing.T) {
+	for _, seed := range seeds(t, 5) {
+		s := makeSimulator(t, seed, 3)
+		s.run(3 * time.Second)
+		if len(s.leaders) == 0 {
+			s.fatalf("no leader elected")
+		}
+		s.cleanup()
+	}
+}
+
+func TestSimSafety(t *testing.T) {
+	for _, seed := range seeds(t, 3) {
+		s := makeSimulator(t, seed, 5)
+		s.dropRate = 0.05
+		s.workload(20 * time.Second)
+		s.checkRecovered()
+		s.cleanup()
+	}
+}
+
+// 每个seed运行两次，两次的运行过程必须完全相同
+func TestSimReplay(t *testing.T) {
+	for _, seed := range seeds(t, 3) {
+		var traces [][]string
+		var hashes []uint64
+		for run := 0; run < 2; run++ {
+			s := makeSimulator(t, seed, 5)
+			s.dropRate = 0.05
+			s.workload(10 * time.Second)
+			traces = append(traces, s.trace)
+			hashes = append(hashes, s.traceHash())
+			s.cleanup()
+		}
+		if hashes[0] != hashes[1] {
+			a, b := traces[0], traces[1]
+			i := 0
+			for i < len(a) && i < len(b) && a[i] == b[i] {
+				i++
+			}
+			t.Fatalf("seed %d produced different runs: %x vs %x, first difference at trace line %d: %q vs %q",
+				seed, hashes[0], hashes[1], i, line(a, i), line(b, i))
+		}
+	}
+}
+
+func line(trace []string, i int) string {
+	if i < len(trace) {
+		return trace[i]
+	}
+	return "<end>"
+}
@@ -0,0 +1,739 @@
+package simtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+	pst "hckvstore/persister"
+	"hckvstore/raft"
+	RPC "hckvstore/rpc/raftrpc"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// 确定性模拟：N个raft.Raft实例运行在虚拟时钟上，节点之间通过模拟网络通信。
+// 模拟器每一步只触发一个事件(一个到期的定时器，或者一条消息的投递/响应)，
+// 等所有Raft的goroutine都在等待之后再触发下一个事件，然后检查安全性。
+// Raft的goroutine由模拟器作为raft.Scheduler调度，同一时刻只有一个在运行，
+// 它们在Wait、RPC调用和退出时把控制权交还给模拟器，所以不会同时争抢rf.mu。
+// 延迟、丢包、选举超时和测试的操作都来自同一个seed，同一个seed的运行过程完全相同。
+
+var (
+	errUnreachable = errors.New("sim: unreachable")
+	errClosed      = errors.New("sim: transport closed")
+)
+
+// 模拟网络中RPC的超时，请求或响应丢失之后调用者在这个时间之后收到错误
+const rpcTimeout = time.Second
+
+// 模拟器调度的goroutine在等待时持有一个waiter，被唤醒时从wake收到Wait的返回值
+type waiter struct {
+	chs    []chan bool
+	result int
+	wake   chan int
+}
+
+// 到期时唤醒w，w为nil时向ch发送时间(Clock.After)
+type timer struct {
+	at  time.Time
+	seq int64
+	ch  chan time.Time
+	w   *waiter
+}
+
+type call struct {
+	seq    int64
+	from   string
+	to     string
+	method string
+	req    proto.Message
+	reply  proto.Message
+	err    error
+	w      *waiter // 等待响应的调用者
+}
+
+// proto的文本格式不保证稳定，trace和排序都使用确定性的二进制编码
+func marshal(m proto.Message) []byte {
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	return data
+}
+
+type eventKind int
+
+const (
+	deliverRequest eventKind = iota
+	deliverReply
+	deliverError
+)
+
+type event struct {
+	at   time.Time
+	seq  int64
+	kind eventKind
+	call *call
+}
+
+type simNode struct {
+	rf        *raft.Raft
+	persister *pst.Persister
+}
+
+type simulator struct {
+	t     *testing.T
+	seed  int64
+	rand  *rand.Rand
+	steps int
+	trace []string
+
+	// 网络参数
+	minDelay time.Duration
+	maxDelay time.Duration
+	dropRate float64
+
+	mu        sync.Mutex
+	now       time.Time
+	timers    []*timer
+	timerSeq  int64
+	events    []*event
+	requests  []*call // 上一次flush之后发出的请求
+	replies   []*call // 上一次flush之后处理完的请求
+	seq       int64
+	waiting   []*waiter     // 在Wait中等待通知的goroutine
+	ready     []*waiter     // 可以运行的goroutine，按唤醒的顺序运行
+	yield     chan struct{} // 正在运行的goroutine等待或者退出时把控制权交还给模拟器
+	handlers  map[string]raft.Transport
+	servers   map[string]RPC.RAFTServer
+	partition map[string]int
+
+	addrs []string
+	dirs  []string
+	nodes []*simNode
+
+	// 安全性检查需要的历史
+	leaders   map[int32]string // term -> leader
+	committed map[int32]committedEntry
+}
+
+type committedEntry struct {
+	entry *RPC.Entry
+	term  int32 // 观察到提交时提交者的term，更大term的leader必须包含这条日志
+}
+
+func makeSimulator(t *testing.T, seed int64, n int) *simulator {
+	s := &simulator{
+		t:         t,
+		seed:      seed,
+		rand:      rand.New(rand.NewSource(seed)),
+		minDelay:  time.Millisecond,
+		maxDelay:  20 * time.Millisecond,
+		now:       time.Unix(0, 0),
+		yield:     make(chan struct{}),
+		handlers:  make(map[string]raft.Transport),
+		servers:   make(map[string]RPC.RAFTServer),
+		leaders:   make(map[int32]string),
+		committed: make(map[int32]committedEntry),
+	}
+	for i := 0; i < n; i++ {
+		s.addrs = append(s.addrs, fmt.Sprintf("sim-%d", i))
+		s.dirs = append(s.dirs, t.TempDir())
+		s.nodes = append(s.nodes, nil)
+	}
+	for i := 0; i < n; i++ {
+		s.start(i)
+	}
+	return s
+}
+
+func (s *simulator) fatalf(format string, args ...interface{}) {
+	s.t.Helper()
+	s.t.Fatalf("seed %d, step %d, time %v: %s (replay with SIM_SEED=%d)",
+		s.seed, s.steps, s.now.Sub(time.Unix(0, 0)), fmt.Sprintf(format, args...), s.seed)
+}
+
+func (s *simulator) tracef(format string, args ...interface{}) {
+	s.trace = append(s.trace, fmt.Sprintf("%v ", s.now.Sub(time.Unix(0, 0)))+fmt.Sprintf(format, args...))
+}
+
+// 运行过程的摘要，同一个seed的两次运行必须相同
+func (s *simulator) traceHash() uint64 {
+	h := fnv.New64a()
+	for _, line := range s.trace {
+		h.Write([]byte(line))
+		h.Write([]byte{'\n'})
+	}
+	return h.Sum64()
+}
+
+// 启动(或重启)第i个节点
+func (s *simulator) start(i int) {
+	nd := &simNode{persister: &pst.Persister{}}
+	nd.persister.Init(s.dirs[i])
+	env := raft.Env{
+		Transport: &simTransport{s: s, address: s.addrs[i]},
+		Clock:     simClock{s},
+		Scheduler: s,
+		Rand:      rand.New(rand.NewSource(s.rand.Int63())),
+		// 延迟由模拟网络决定
+		NoSendJitter: true,
+	}
+	nd.rf = raft.MakeRaftWithEnv(s.addrs[i], s.addrs, nd.persister, &sync.Mutex{}, store.MakeStore(nd.persister, store.DefaultSessionTTL), 0, -1, 100*time.Millisecond, env)
+	s.nodes[i] = nd
+	s.settle()
+	s.tracef("start %d", i)
+}
+
+func (s *simulator) crash(i int) {
+	s.nodes[i].rf.Kill()
+	s.settle()
+	s.nodes[i].persister.Close()
+	s.nodes[i] = nil
+	s.tracef("crash %d", i)
+}
+
+// cleanup关闭所有节点，然后继续运行到没有事件为止，让被关闭节点的goroutine都退出
+func (s *simulator) cleanup() {
+	for i := range s.nodes {
+		if s.nodes[i] != nil {
+			s.crash(i)
+		}
+	}
+	for s.step() {
+	}
+}
+
+// Partition把节点分成互不连通的组，不在任何组中的节点被隔离
+func (s *simulator) setPartition(groups ...[]int) {
+	s.mu.Lock()
+	s.partition = make(map[string]int)
+	for g, group := range groups {
+		for _, i := range group {
+			s.partition[s.addrs[i]] = g
+		}
+	}
+	s.mu.Unlock()
+	s.tracef("partition %v", groups)
+}
+
+func (s *simulator) heal() {
+	s.mu.Lock()
+	s.partition = nil
+	s.mu.Unlock()
+	s.tracef("heal")
+}
+
+// 调用者需要持有s.mu
+func (s *simulator) reachable(from, to string) bool {
+	if _, ok := s.servers[to]; !ok {
+		return false
+	}
+	if _, ok := s.servers[from]; !ok {
+		return false
+	}
+	if s.partition == nil {
+		return true
+	}
+	g1, ok1 := s.partition[from]
+	g2, ok2 := s.partition[to]
+	return ok1 && ok2 && g1 == g2
+}
+
+// 调用者需要持有s.mu
+func (s *simulator) delay() time.Duration {
+	return s.minDelay + time.Duration(s.rand.Int63n(int64(s.maxDelay-s.minDelay)+1))
+}
+
+// settle依次运行所有可以运行的goroutine，直到它们都在等待定时器、通知或者RPC响应。
+// 这时系统只能被下一个事件推进，模拟器可以安全地读取状态、触发事件
+func (s *simulator) settle() {
+	for {
+		s.mu.Lock()
+		if len(s.ready) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		w := s.ready[0]
+		s.ready = s.ready[1:]
+		s.mu.Unlock()
+		// 交出控制权，等这个goroutine再次等待或者退出
+		w.wake <- w.result
+		<-s.yield
+	}
+}
+
+// flush为上一步中新发出的请求和新产生的响应安排投递时间。
+// goroutine依次运行，消息发出的顺序和抽取随机数的顺序都是确定的
+func (s *simulator) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.requests {
+		s.seq++
+		c.seq = s.seq
+		if s.rand.Float64() < s.dropRate {
+			s.schedule(s.now.Add(rpcTimeout), deliverError, c)
+		} else {
+			s.schedule(s.now.Add(s.delay()), deliverRequest, c)
+		}
+	}
+	s.requests = nil
+	for _, c := range s.replies {
+		if c.err != nil {
+			s.schedule(s.now, deliverError, c)
+		} else if s.rand.Float64() < s.dropRate {
+			s.schedule(s.now.Add(rpcTimeout), deliverError, c)
+		} else {
+			s.schedule(s.now.Add(s.delay()), deliverReply, c)
+		}
+	}
+	s.replies = nil
+}
+
+// 调用者需要持有s.mu
+func (s *simulator) schedule(at time.Time, kind eventKind, c *call) {
+	s.events = append(s.events, &event{at: at, seq: c.seq, kind: kind, call: c})
+}
+
+// step触发下一个事件并检查安全性，没有事件时返回false。
+// 调用step之前系统已经静止：上一步和测试的每个操作结束时都会调用settle
+func (s *simulator) step() bool {
+	s.flush()
+	s.mu.Lock()
+	ti := -1
+	for i, tm := range s.timers {
+		if ti < 0 || tm.at.Before(s.timers[ti].at) || tm.at.Equal(s.timers[ti].at) && tm.seq < s.timers[ti].seq {
+			ti = i
+		}
+	}
+	ei := -1
+	for i, e := range s.events {
+		if ei < 0 || e.at.Before(s.events[ei].at) || e.at.Equal(s.events[ei].at) && e.seq < s.events[ei].seq {
+			ei = i
+		}
+	}
+	if ti < 0 && ei < 0 {
+		s.mu.Unlock()
+		return false
+	}
+	s.steps++
+	if ei < 0 || ti >= 0 && !s.events[ei].at.Before(s.timers[ti].at) {
+		// 同时到期的定时器按创建的顺序逐个触发
+		tm := s.timers[ti]
+		s.timers = append(s.timers[:ti], s.timers[ti+1:]...)
+		s.now = tm.at
+		if tm.w != nil {
+			s.wakeLocked(tm.w, -1)
+		} else {
+			tm.ch <- tm.at
+		}
+		s.mu.Unlock()
+		s.tracef("timer %d", tm.seq)
+	} else {
+		e := s.events[ei]
+		s.events = append(s.events[:ei], s.events[ei+1:]...)
+		s.now = e.at
+		s.deliver(e)
+		s.mu.Unlock()
+	}
+	s.settle()
+	s.check()
+	return true
+}
+
+// 调用者需要持有s.mu
+func (s *simulator) deliver(e *event) {
+	c := e.call
+	switch e.kind {
+	case deliverRequest:
+		h, ok := s.servers[c.to]
+		if !ok || !s.reachable(c.from, c.to) {
+			c.err = errUnreachable
+			s.schedule(s.now.Add(rpcTimeout), deliverError, c)
+			s.tracef("drop %d %s %s->%s", c.seq, c.method, c.from, c.to)
+			return
+		}
+		s.tracef("request %d %s %s->%s", c.seq, c.method, c.from, c.to)
+		req := proto.Clone(c.req)
+		s.goLocked(func() {
+			reply, err := handle(h, c.method, req)
+			s.mu.Lock()
+			c.reply, c.err = reply, err
+			s.replies = append(s.replies, c)
+			s.mu.Unlock()
+		})
+	case deliverReply:
+		if !s.reachable(c.to, c.from) {
+			c.err = errUnreachable
+			s.schedule(s.now.Add(rpcTimeout), deliverError, c)
+			s.tracef("drop reply %d", c.seq)
+			return
+		}
+		s.tracef("reply %d %x", c.seq, marshal(c.reply))
+		s.wakeLocked(c.w, 0)
+	case deliverError:
+		if c.err == nil {
+			c.err = errUnreachable
+		}
+		c.reply = nil
+		s.tracef("error %d", c.seq)
+		s.wakeLocked(c.w, 0)
+	}
+}
+
+func handle(h RPC.RAFTServer, method string, req proto.Message) (proto.Message, error) {
+	ctx := context.Background()
+	switch method {
+	case "RequestVote":
+		return h.RequestVote(ctx, req.(*RPC.RequestVoteArgs))
+	case "PreVote":
+		return h.PreVote(ctx, req.(*RPC.RequestVoteArgs))
+	case "AppendEntries":
+		return h.AppendEntries(ctx, req.(*RPC.AppendEntriesArgs))
+	case "InstallSnapshot":
+		return h.InstallSnapshot(ctx, req.(*RPC.InstallSnapshotArgs))
+	case "TimeoutNow":
+		return h.TimeoutNow(ctx, req.(*RPC.TimeoutNowArgs))
+	}
+	panic("unknown method " + method)
+}
+
+// run推进虚拟时间d
+func (s *simulator) run(d time.Duration) {
+	end := s.now.Add(d)
+	for s.now.Before(end) {
+		if !s.step() {
+			return
+		}
+	}
+}
+
+// 向当前的leader提交一条Put，没有leader时返回false
+func (s *simulator) start1(key, value string) bool {
+	for _, nd := range s.nodes {
+		if nd == nil {
+			continue
+		}
+		if index, term, ok := nd.rf.Start(config.Op{Option: "Put", Key: key, Value: value}); ok {
+			s.settle()
+			s.tracef("start %s=%s at %d in term %d", key, value, index, term)
+			return true
+		}
+	}
+	return false
+}
+
+// check在每一步之后检查Raft论文Figure 3中的安全性
+func (s *simulator) check() {
+	states := make([]*raft.LogState, len(s.nodes))
+	for i, nd := range s.nodes {
+		if nd != nil {
+			st := nd.rf.LogState()
+			states[i] = &st
+		}
+	}
+
+	// Election Safety：一个term最多一个leader
+	for i, st := range states {
+		if st == nil || !st.IsLeader {
+			continue
+		}
+		if l, ok := s.leaders[st.Term]; ok && l != s.addrs[i] {
+			s.fatalf("term %d has two leaders: %v and %v", st.Term, l, s.addrs[i])
+		}
+		if _, ok := s.leaders[st.Term]; !ok {
+			s.leaders[st.Term] = s.addrs[i]
+			s.tracef("%v leads term %d", s.addrs[i], st.Term)
+		}
+	}
+
+	// Log Matching：两个日志在某个下标处的term相同，则之前的日志完全相同
+	for i := range states {
+		for j := i + 1; j < len(states); j++ {
+			if states[i] != nil && states[j] != nil {
+				s.checkLogMatching(i, j, states[i], states[j])
+			}
+		}
+	}
+
+	// State Machine Safety：同一个下标提交(apply)的日志在所有节点上相同
+	for i, st := range states {
+		if st == nil {
+			continue
+		}
+		for idx := st.FirstIndex; idx <= st.CommitIndex; idx++ {
+			e := st.Entries[idx-st.FirstIndex]
+			if c, ok := s.committed[idx]; ok {
+				if !sameEntry(c.entry, e) {
+					s.fatalf("%v committed %v at index %d, but %v was committed before", s.addrs[i], e, idx, c.entry)
+				}
+				if st.Term < c.term {
+					c.term = st.Term
+					s.committed[idx] = c
+				}
+			} else {
+				s.committed[idx] = committedEntry{entry: e, term: st.Term}
+			}
+		}
+	}
+
+	// Leader Completeness：提交之后当选的leader包含所有已提交的日志
+	for i, st := range states {
+		if st == nil || !st.IsLeader {
+			continue
+		}
+		for idx, c := range s.committed {
+			if st.Term <= c.term || idx < st.FirstIndex {
+				continue
+			}
+			if idx >= st.FirstIndex+int32(len(st.Entries)) || !sameEntry(st.Entries[idx-st.FirstIndex], c.entry) {
+				s.fatalf("leader %v of term %d is missing committed entry %v at index %d", s.addrs[i], st.Term, c.entry, idx)
+			}
+		}
+	}
+}
+
+func (s *simulator) checkLogMatching(i, j int, a, b *raft.LogState) {
+	lo := a.FirstIndex
+	if b.FirstIndex > lo {
+		lo = b.FirstIndex
+	}
+	hi := a.FirstIndex + int32(len(a.Entries)) - 1
+	if h := b.FirstIndex + int32(len(b.Entries)) - 1; h < hi {
+		hi = h
+	}
+	matched := false
+	for idx := hi; idx >= lo; idx-- {
+		ea, eb := a.Entries[idx-a.FirstIndex], b.Entries[idx-b.FirstIndex]
+		if !matched {
+			matched = ea.Term == eb.Term
+		}
+		if matched && !sameEntry(ea, eb) {
+			s.fatalf("logs of %v and %v match at a later index but differ at index %d: %v vs %v", s.addrs[i], s.addrs[j], idx, ea, eb)
+		}
+	}
+}
+
+func sameEntry(a, b *RPC.Entry) bool {
+	return a.Index == b.Index && a.Term == b.Term && a.Type == b.Type && bytes.Equal(a.Data, b.Data)
+}
+
+// 模拟器作为raft.Scheduler：Go启动的goroutine先进入ready队列，由settle逐个运行
+
+func (s *simulator) Go(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.goLocked(f)
+}
+
+// 调用者需要持有s.mu
+func (s *simulator) goLocked(f func()) {
+	w := &waiter{wake: make(chan int, 1)}
+	s.ready = append(s.ready, w)
+	go func() {
+		<-w.wake
+		f()
+		s.yield <- struct{}{}
+	}()
+}
+
+func (s *simulator) Notify(ch chan bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.waiting {
+		for i, c := range w.chs {
+			if c == ch {
+				s.wakeLocked(w, i)
+				return
+			}
+		}
+	}
+	if len(ch) == 0 {
+		ch <- true
+	}
+}
+
+func (s *simulator) Wait(d time.Duration, chs ...chan bool) int {
+	s.mu.Lock()
+	for i, ch := range chs {
+		if len(ch) > 0 {
+			<-ch
+			s.mu.Unlock()
+			return i
+		}
+	}
+	if d <= 0 {
+		s.mu.Unlock()
+		return -1
+	}
+	w := &waiter{chs: chs, wake: make(chan int, 1)}
+	s.waiting = append(s.waiting, w)
+	s.addTimer(d, nil, w)
+	s.mu.Unlock()
+	return s.park(w)
+}
+
+// 正在运行的goroutine把控制权交还给模拟器，等待w被唤醒。调用者不能持有s.mu
+func (s *simulator) park(w *waiter) int {
+	s.yield <- struct{}{}
+	return <-w.wake
+}
+
+// 把等待中的w移到ready队列，取消它的定时器。调用者需要持有s.mu
+func (s *simulator) wakeLocked(w *waiter, result int) {
+	for i, x := range s.waiting {
+		if x == w {
+			s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+			break
+		}
+	}
+	for i, tm := range s.timers {
+		if tm.w == w {
+			s.timers = append(s.timers[:i], s.timers[i+1:]...)
+			break
+		}
+	}
+	w.result = result
+	s.ready = append(s.ready, w)
+}
+
+// 调用者需要持有s.mu
+func (s *simulator) addTimer(d time.Duration, ch chan time.Time, w *waiter) {
+	s.timerSeq++
+	s.timers = append(s.timers, &timer{at: s.now.Add(d), seq: s.timerSeq, ch: ch, w: w})
+}
+
+type simClock struct {
+	s *simulator
+}
+
+func (c simClock) Now() time.Time {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	return c.s.now
+}
+
+// Raft的后台goroutine通过Scheduler等待，After和Sleep只在调用者的goroutine中使用
+func (c simClock) After(d time.Duration) <-chan time.Time {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.s.addTimer(d, ch, nil)
+	return ch
+}
+
+func (c simClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+type simTransport struct {
+	s       *simulator
+	address string
+	closed  bool
+	serve   *waiter // Serve等待Close
+}
+
+func (t *simTransport) Serve(address string, handler RPC.RAFTServer) {
+	t.s.mu.Lock()
+	if t.closed {
+		t.s.mu.Unlock()
+		return
+	}
+	t.s.handlers[address] = t
+	t.s.servers[address] = handler
+	t.serve = &waiter{wake: make(chan int, 1)}
+	t.s.mu.Unlock()
+	t.s.park(t.serve)
+}
+
+func (t *simTransport) Client(address string) (RPC.RAFTClient, error) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	if t.closed {
+		return nil, errClosed
+	}
+	return &simClient{t: t, to: address}, nil
+}
+
+func (t *simTransport) Retain(keep func(address string) bool) {}
+
+func (t *simTransport) Close() {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	if t.s.handlers[t.address] == t {
+		delete(t.s.handlers, t.address)
+		delete(t.s.servers, t.address)
+	}
+	if t.serve != nil {
+		t.s.wakeLocked(t.serve, 0)
+	}
+}
+
+// 发出请求，交还控制权，等待模拟器投递响应
+func (t *simTransport) call(to string, method string, req proto.Message) (proto.Message, error) {
+	c := &call{from: t.address, to: to, method: method, req: proto.Clone(req), w: &waiter{wake: make(chan int, 1)}}
+	t.s.mu.Lock()
+	t.s.requests = append(t.s.requests, c)
+	t.s.mu.Unlock()
+	t.s.park(c.w)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return proto.Clone(c.reply), nil
+}
+
+type simClient struct {
+	t  *simTransport
+	to string
+}
+
+func (c *simClient) RequestVote(ctx context.Context, in *RPC.RequestVoteArgs, opts ...grpc.CallOption) (*RPC.RequestVoteReply, error) {
+	reply, err := c.t.call(c.to, "RequestVote", in)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.RequestVoteReply), nil
+}
+
+func (c *simClient) PreVote(ctx context.Context, in *RPC.RequestVoteArgs, opts ...grpc.CallOption) (*RPC.RequestVoteReply, error) {
+	reply, err := c.t.call(c.to, "PreVote", in)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.RequestVoteReply), nil
+}
+
+func (c *simClient) AppendEntries(ctx context.Context, in *RPC.AppendEntriesArgs, opts ...grpc.CallOption) (*RPC.AppendEntriesReply, error) {
+	reply, err := c.t.call(c.to, "AppendEntries", in)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.AppendEntriesReply), nil
+}
+
+func (c *simClient) InstallSnapshot(ctx context.Context, in *RPC.InstallSnapshotArgs, opts ...grpc.CallOption) (*RPC.InstallSnapshotReply, error) {
+	reply, err := c.t.call(c.to, "InstallSnapshot", in)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.InstallSnapshotReply), nil
+}
+
+func (c *simClient) TimeoutNow(ctx context.Context, in *RPC.TimeoutNowArgs, opts ...grpc.CallOption) (*RPC.TimeoutNowReply, error) {
+	reply, err := c.t.call(c.to, "TimeoutNow", in)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*RPC.TimeoutNowReply), nil
+}
@@ -0,0 +1,74 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+)
+
+func TestCompareAndSwapValue(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	if reply := f.apply(config.Op{Option: store.OpCASValue, Key: "k", Value: "a", Id: 1, Seq: 1}); !reply.Mismatch {
+		t.Fatalf("CAS on a missing key succeeded: %+v", reply)
+	}
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 2})
+	reply := f.apply(config.Op{Option: store.OpCASValue, Key: "k", Expected: "x", Value: "b", Id: 1, Seq: 3})
+	if !reply.Mismatch || reply.Value != "a" {
+		t.Fatalf("CAS with a wrong expected value returned %+v, want mismatch with a", reply)
+	}
+	reply = f.apply(config.Op{Option: store.OpCASValue, Key: "k", Expected: "a", Value: "b", Id: 1, Seq: 4})
+	if reply.Mismatch || f.get("k") != "b" {
+		t.Fatalf("CAS returned %+v, k=%q", reply, f.get("k"))
+	}
+	// 重试返回第一次执行的结果，即使条件已经不成立
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "c", Id: 2, Seq: 1})
+	reply = f.apply(config.Op{Option: store.OpCASValue, Key: "k", Expected: "a", Value: "b", Id: 1, Seq: 4})
+	if reply.Mismatch || f.get("k") != "c" {
+		t.Fatalf("retried CAS returned %+v, k=%q", reply, f.get("k"))
+	}
+}
+
+func TestCompareVersionAndSwap(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	// 版本0表示key不存在
+	reply := f.apply(config.Op{Option: store.OpCASVersion, Key: "k", ExpectedVersion: 0, Value: "a", Id: 1, Seq: 1})
+	if reply.Mismatch || reply.Version != int64(f.index) {
+		t.Fatalf("CAS on version 0 returned %+v", reply)
+	}
+	if _, version := f.s.GetVersion("k"); version != reply.Version {
+		t.Fatalf("GetVersion returned %d, want %d", version, reply.Version)
+	}
+	stale := reply.Version
+	f.apply(config.Op{Option: "Append", Key: "k", Value: "b", Id: 2, Seq: 1})
+	reply = f.apply(config.Op{Option: store.OpCASVersion, Key: "k", ExpectedVersion: stale, Value: "x", Id: 1, Seq: 2})
+	if !reply.Mismatch || reply.Value != "ab" || reply.Version != int64(f.index-1) {
+		t.Fatalf("CAS on a stale version returned %+v", reply)
+	}
+	reply = f.apply(config.Op{Option: store.OpCASVersion, Key: "k", ExpectedVersion: reply.Version, Value: "x", Id: 1, Seq: 3})
+	if reply.Mismatch || f.get("k") != "x" {
+		t.Fatalf("CAS on the current version returned %+v, k=%q", reply, f.get("k"))
+	}
+	f.apply(config.Op{Option: "Delete", Key: "k", Id: 1, Seq: 4})
+	if value, version := f.s.GetVersion("k"); value != "" || version != 0 {
+		t.Fatalf("GetVersion after Delete returned %q, %d", value, version)
+	}
+}
+
+func TestConditionalPut(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	if reply := f.apply(config.Op{Option: store.OpPutIfPresent, Key: "k", Value: "a", Id: 1, Seq: 1}); !reply.Mismatch {
+		t.Fatalf("PutIfPresent on a missing key returned %+v", reply)
+	}
+	if reply := f.apply(config.Op{Option: store.OpPutIfAbsent, Key: "k", Value: "a", Id: 1, Seq: 2}); reply.Mismatch {
+		t.Fatalf("PutIfAbsent on a missing key returned %+v", reply)
+	}
+	reply := f.apply(config.Op{Option: store.OpPutIfAbsent, Key: "k", Value: "b", Id: 1, Seq: 3})
+	if !reply.Mismatch || reply.Value != "a" {
+		t.Fatalf("PutIfAbsent on an existing key returned %+v", reply)
+	}
+	if reply := f.apply(config.Op{Option: store.OpPutIfPresent, Key: "k", Value: "c", Id: 1, Seq: 4}); reply.Mismatch || f.get("k") != "c" {
+		t.Fatalf("PutIfPresent returned %+v, k=%q", reply, f.get("k"))
+	}
+}
@@ -0,0 +1,82 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+)
+
+func grant(t *testing.T, f *fixture, ttl int64, now int64) int64 {
+	reply := f.apply(config.Op{Option: store.OpLeaseGrant, TTL: ttl, Time: now})
+	if reply.Err != "" || reply.Lease != int64(f.index) {
+		t.Fatalf("LeaseGrant returned %+v", reply)
+	}
+	return reply.Lease
+}
+
+// lease只在LeaseExpire日志的时间超过到期时间时删除，续约推迟到期时间
+func TestLeaseExpire(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	id := grant(t, f, 1000, 10000)
+	f.apply(config.Op{Option: "Put", Key: "node/1", Value: "up", Lease: id, Time: 10100})
+	f.apply(config.Op{Option: "Put", Key: "other", Value: "x", Time: 10100})
+	if leases := f.s.Leases(); len(leases) != 1 || leases[0] != (store.LeaseInfo{ID: id, TTL: 1000, Expiry: 11000}) {
+		t.Fatalf("Leases returned %+v", leases)
+	}
+
+	f.apply(config.Op{Option: store.OpLeaseKeepAlive, Lease: id, Time: 10500})
+	f.apply(config.Op{Option: store.OpLeaseExpire, Lease: id, Time: 11200})
+	if f.get("node/1") != "up" {
+		t.Fatalf("key of a renewed lease was deleted")
+	}
+
+	w, _, _ := f.s.Watch("node/", true, 0)
+	defer w.Close()
+	f.apply(config.Op{Option: store.OpLeaseExpire, Lease: id, Time: 11500})
+	if f.get("node/1") != "" || f.get("other") != "x" || len(f.s.Leases()) != 0 {
+		t.Fatalf("after expiry node/1=%q other=%q leases=%+v", f.get("node/1"), f.get("other"), f.s.Leases())
+	}
+	if got := next(t, w); len(got) != 1 || got[0].Type != store.EventDelete || got[0].Key != "node/1" {
+		t.Fatalf("expiry events %+v", got)
+	}
+	if reply := f.apply(config.Op{Option: store.OpLeaseKeepAlive, Lease: id, Time: 11600}); reply.Err != store.ErrLeaseNotFound {
+		t.Fatalf("KeepAlive of an expired lease returned %+v", reply)
+	}
+}
+
+func TestLeaseAttach(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	id := grant(t, f, 1000, 0)
+	f.apply(config.Op{Option: "Put", Key: "a", Value: "1", Lease: id})
+	f.apply(config.Op{Option: "Put", Key: "b", Value: "1", Lease: id})
+	f.apply(config.Op{Option: "Put", Key: "c", Value: "1", Lease: id})
+	// 不带lease的Put解除关联，Append保留关联，删除之后重新写入的key不再关联
+	f.apply(config.Op{Option: "Put", Key: "a", Value: "2"})
+	f.apply(config.Op{Option: "Append", Key: "b", Value: "2"})
+	f.apply(config.Op{Option: "Delete", Key: "c"})
+	f.apply(config.Op{Option: "Put", Key: "c", Value: "2"})
+
+	if reply := f.apply(config.Op{Option: "Put", Key: "d", Value: "1", Lease: 12345}); reply.Err != store.ErrLeaseNotFound || f.get("d") != "" {
+		t.Fatalf("Put with a missing lease returned %+v", reply)
+	}
+	reply := f.apply(config.Op{Option: store.OpTxn, Success: []config.Op{
+		{Option: "Put", Key: "e", Value: "1"},
+		{Option: "Put", Key: "d", Value: "1", Lease: 12345},
+	}})
+	if reply.Err != store.ErrLeaseNotFound || f.get("e") != "" {
+		t.Fatalf("Txn with a missing lease returned %+v, e=%q", reply, f.get("e"))
+	}
+
+	// lease包含在快照中
+	g := makeStore(t, time.Minute)
+	g.s.Restore(f.index, f.s.Snapshot())
+	g.index = f.index
+	if reply := g.apply(config.Op{Option: store.OpLeaseRevoke, Lease: id}); reply.Err != "" {
+		t.Fatalf("LeaseRevoke on the restored store returned %+v", reply)
+	}
+	if g.get("a") != "2" || g.get("b") != "" || g.get("c") != "2" {
+		t.Fatalf("after revoke a=%q b=%q c=%q", g.get("a"), g.get("b"), g.get("c"))
+	}
+}
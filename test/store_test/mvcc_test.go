@@ -0,0 +1,125 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+	pst "hckvstore/persister"
+)
+
+type versioned struct {
+	value   string
+	version int64
+}
+
+func (f *fixture) getAt(key string, rev int64) versioned {
+	value, version, err := f.s.GetAt(key, rev)
+	if err != nil {
+		f.t.Fatalf("GetAt(%q, %d) failed: %v", key, rev, err)
+	}
+	return versioned{value, version}
+}
+
+// 读任意一个revision都能得到当时的值，删除之后到下一次写入之前读不到key
+func TestMVCCGetAt(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a"})
+	f.apply(config.Op{Option: "Append", Key: "k", Value: "b"})
+	f.apply(config.Op{Option: "Delete", Key: "k"})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "c"})
+
+	want := []versioned{{"a", 1}, {"ab", 2}, {"", 0}, {"c", 4}}
+	for i, w := range want {
+		if got := f.getAt("k", int64(i+1)); got != w {
+			t.Fatalf("k at revision %d is %+v, want %+v", i+1, got, w)
+		}
+	}
+	if _, _, err := f.s.GetAt("k", 5); err != store.ErrFutureRevision {
+		t.Fatalf("read at a revision that is not applied returned %v", err)
+	}
+}
+
+func TestMVCCScanAt(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	// key中包含0x00，编码之后的顺序仍然要和key的顺序一致
+	f.apply(config.Op{Option: "Put", Key: "a", Value: "1"})
+	f.apply(config.Op{Option: "Put", Key: "a\x00b", Value: "2"})
+	f.apply(config.Op{Option: "Put", Key: "b", Value: "3"})
+	f.apply(config.Op{Option: "Delete", Key: "a"})
+	f.apply(config.Op{Option: "Put", Key: "c", Value: "4"})
+	f.apply(config.Op{Option: "Put", Key: "b", Value: "5"})
+
+	cases := []struct {
+		rev     int64
+		reverse bool
+		want    []string
+	}{
+		{2, false, []string{"a", "a\x00b"}},
+		{3, false, []string{"a", "a\x00b", "b"}},
+		{4, false, []string{"a\x00b", "b"}},
+		{6, false, []string{"a\x00b", "b", "c"}},
+		{3, true, []string{"b", "a\x00b", "a"}},
+		{5, true, []string{"c", "b", "a\x00b"}},
+	}
+	for _, c := range cases {
+		if got := scanAll(t, f.s, store.ScanOptions{Revision: c.rev, Reverse: c.reverse, Limit: 1}); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("scan at %d (reverse %v) returned %q, want %q", c.rev, c.reverse, got, c.want)
+		}
+	}
+
+	kvs, _, err := f.s.Scan(store.ScanOptions{Start: "a\x00", End: "c", Revision: 5})
+	want := []store.KeyValue{{Key: "a\x00b", Value: "2", Version: 2}, {Key: "b", Value: "3", Version: 3}}
+	if err != nil || !reflect.DeepEqual(kvs, want) {
+		t.Fatalf("range scan at 5 returned %+v, %v, want %+v", kvs, err, want)
+	}
+}
+
+func TestMVCCCompact(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a"})
+	f.apply(config.Op{Option: "Put", Key: "gone", Value: "x"})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "b"})
+	f.apply(config.Op{Option: "Delete", Key: "gone"})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "c"})
+
+	if reply := f.apply(config.Op{Option: store.OpCompact, Revision: 7}); reply.Err == "" {
+		t.Fatalf("compact at a future revision succeeded")
+	}
+	if reply := f.apply(config.Op{Option: store.OpCompact, Revision: 4}); reply.Err != "" || reply.Version != 4 {
+		t.Fatalf("compact returned %+v", reply)
+	}
+	// 再次压缩到更早的revision不做任何事
+	if reply := f.apply(config.Op{Option: store.OpCompact, Revision: 2}); reply.Err != "" || reply.Version != 4 {
+		t.Fatalf("compact to an older revision returned %+v", reply)
+	}
+
+	if _, _, err := f.s.GetAt("k", 3); err == nil {
+		t.Fatalf("read at a compacted revision succeeded")
+	} else if c, ok := err.(*store.CompactedError); !ok || c.Revision != 4 {
+		t.Fatalf("read at a compacted revision returned %v", err)
+	}
+	if _, _, err := f.s.Scan(store.ScanOptions{Revision: 3}); err == nil {
+		t.Fatalf("scan at a compacted revision succeeded")
+	}
+	if got := f.getAt("k", 4); got != (versioned{"b", 3}) {
+		t.Fatalf("k at the compact revision is %+v", got)
+	}
+	if got := f.getAt("k", 5); got != (versioned{"c", 5}) {
+		t.Fatalf("k at revision 5 is %+v", got)
+	}
+	if got := f.getAt("gone", 4); got != (versioned{}) {
+		t.Fatalf("deleted key at the compact revision is %+v", got)
+	}
+
+	// 只剩下k在revision 3和5的版本
+	versions := 0
+	f.persister.ScanPrefix(pst.ReservedPrefix+"mvcc/", func(key string, value []byte) {
+		versions++
+	})
+	if versions != 2 {
+		t.Fatalf("%d versions left after compaction, want 2", versions)
+	}
+}
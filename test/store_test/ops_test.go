@@ -0,0 +1,58 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+)
+
+func TestAppend(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Append", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1000})
+	f.apply(config.Op{Option: "Append", Key: "k", Value: "b", Id: 1, Seq: 2, Time: 1001})
+	// 超时重试的Append不会再次追加
+	f.apply(config.Op{Option: "Append", Key: "k", Value: "b", Id: 1, Seq: 2, Time: 1002})
+	f.apply(config.Op{Option: "Append", Key: "k", Value: "c", Id: 2, Seq: 1, Time: 1003})
+	if got := f.get("k"); got != "abc" {
+		t.Fatalf("k=%q, want abc", got)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1000})
+	f.apply(config.Op{Option: "Put", Key: "other", Value: "x", Id: 1, Seq: 2, Time: 1001})
+	f.apply(config.Op{Option: "Delete", Key: "k", Id: 1, Seq: 3, Time: 1002})
+	if got := f.get("k"); got != "" {
+		t.Fatalf("k=%q after Delete", got)
+	}
+	// 删除不存在的key不是错误
+	if reply := f.apply(config.Op{Option: "Delete", Key: "missing", Id: 1, Seq: 4, Time: 1003}); reply.Err != "" {
+		t.Fatalf("Delete of a missing key returned %+v", reply)
+	}
+	f.apply(config.Op{Option: "Append", Key: "k", Value: "b", Id: 1, Seq: 5, Time: 1004})
+	if got := f.get("k"); got != "b" {
+		t.Fatalf("k=%q after Append to a deleted key, want b", got)
+	}
+
+	// 被删除的key不在快照中，恢复之后也不会重新出现
+	f.apply(config.Op{Option: "Delete", Key: "other", Id: 1, Seq: 6, Time: 1005})
+	g := makeStore(t, time.Minute)
+	g.apply(config.Op{Option: "Put", Key: "other", Value: "y", Id: 2, Seq: 1, Time: 900})
+	g.s.Restore(f.index, f.s.Snapshot())
+	if got := g.get("other"); got != "" {
+		t.Fatalf("deleted key other=%q after Restore", got)
+	}
+	if got := g.get("k"); got != "b" {
+		t.Fatalf("k=%q after Restore, want b", got)
+	}
+}
+
+func TestUnknownOp(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	if reply := f.apply(config.Op{Option: "Frobnicate", Key: "k", Id: 1, Seq: 1, Time: 1000}); reply.Err != store.ErrUnknownOp {
+		t.Fatalf("unknown op returned %+v", reply)
+	}
+}
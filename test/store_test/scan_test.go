@@ -0,0 +1,80 @@
+package storetest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+)
+
+// 按页读完整个范围，返回所有key
+func scanAll(t *testing.T, s *store.Store, opts store.ScanOptions) []string {
+	var keys []string
+	for pages := 0; ; pages++ {
+		if pages > 100 {
+			t.Fatalf("scan %+v does not terminate", opts)
+		}
+		kvs, next, err := s.Scan(opts)
+		if err != nil {
+			t.Fatalf("scan %+v failed: %v", opts, err)
+		}
+		if len(kvs) > opts.Limit && opts.Limit > 0 {
+			t.Fatalf("page of %d keys with limit %d", len(kvs), opts.Limit)
+		}
+		for _, kv := range kvs {
+			keys = append(keys, kv.Key)
+		}
+		if next == nil {
+			return keys
+		}
+		opts.Token = next
+	}
+}
+
+func TestScanPages(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	var want []string
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("k%02d", i)
+		want = append(want, key)
+		// 带会话的请求会写入保留key，Scan不能读到它们
+		f.apply(config.Op{Option: "Put", Key: key, Value: "v" + key, Id: 1, Seq: int64(i + 1)})
+	}
+	f.apply(config.Op{Option: "Put", Key: "other", Value: "x"})
+
+	if got := scanAll(t, f.s, store.ScanOptions{Prefix: "k", Limit: 3}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("forward scan returned %v, want %v", got, want)
+	}
+	var reversed []string
+	for i := len(want) - 1; i >= 0; i-- {
+		reversed = append(reversed, want[i])
+	}
+	if got := scanAll(t, f.s, store.ScanOptions{Prefix: "k", Limit: 4, Reverse: true}); !reflect.DeepEqual(got, reversed) {
+		t.Fatalf("reverse scan returned %v, want %v", got, reversed)
+	}
+	if got := scanAll(t, f.s, store.ScanOptions{Start: "k03", End: "k07", Limit: 2}); !reflect.DeepEqual(got, want[3:7]) {
+		t.Fatalf("range scan returned %v, want %v", got, want[3:7])
+	}
+	if got := scanAll(t, f.s, store.ScanOptions{Limit: 5}); len(got) != 11 || got[10] != "other" {
+		t.Fatalf("full scan returned %v", got)
+	}
+}
+
+func TestScanValues(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "a", Value: "1"})
+	f.apply(config.Op{Option: "Put", Key: "b", Value: "2"})
+	f.apply(config.Op{Option: "Delete", Key: "a"})
+
+	kvs, next, _ := f.s.Scan(store.ScanOptions{})
+	if next != nil || len(kvs) != 1 || kvs[0] != (store.KeyValue{Key: "b", Value: "2", Version: 2}) {
+		t.Fatalf("scan returned %+v, %q", kvs, next)
+	}
+	kvs, _, _ = f.s.Scan(store.ScanOptions{KeysOnly: true})
+	if len(kvs) != 1 || kvs[0].Value != "" || kvs[0].Version != 2 {
+		t.Fatalf("keys-only scan returned %+v", kvs)
+	}
+}
@@ -0,0 +1,148 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+	pst "hckvstore/persister"
+	"hckvstore/raft"
+)
+
+type fixture struct {
+	t         *testing.T
+	persister *pst.Persister
+	s         *store.Store
+	index     int32
+}
+
+func makeStore(t *testing.T, ttl time.Duration) *fixture {
+	p := &pst.Persister{}
+	p.Init(t.TempDir())
+	t.Cleanup(p.Close)
+	return &fixture{t: t, persister: p, s: store.MakeStore(p, ttl)}
+}
+
+func (f *fixture) apply(op config.Op) store.Reply {
+	f.index++
+	result := f.s.Apply(raft.ApplyMsg{CommandValid: true, Command: op.Encode(), CommandIndex: f.index, CommandTerm: 1})
+	reply, ok := result.(store.Reply)
+	if !ok {
+		f.t.Fatalf("Apply(%+v) returned %T, want store.Reply", op, result)
+	}
+	return reply
+}
+
+func (f *fixture) get(key string) string {
+	return f.s.Get(key)
+}
+
+// 重试的请求不会再次执行：另一个客户端的写入不会被重复的旧请求覆盖
+func TestSessionDuplicate(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1000})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "b", Id: 2, Seq: 1, Time: 1001})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1002})
+	if got := f.get("k"); got != "b" {
+		t.Fatalf("k=%q after a retried Put, want b", got)
+	}
+	if reply := f.apply(config.Op{Option: "Put", Key: "k", Value: "c", Id: 1, Seq: 0, Time: 1003}); reply.Err != store.ErrStaleRequest {
+		t.Fatalf("request older than the session returned %+v", reply)
+	}
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "c", Id: 1, Seq: 2, Time: 1004})
+	if got := f.get("k"); got != "c" {
+		t.Fatalf("k=%q after a new Put, want c", got)
+	}
+}
+
+// 会话在TTL内没有新请求就过期，之后的重复请求被当作新请求执行
+func TestSessionExpire(t *testing.T) {
+	f := makeStore(t, time.Second)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1000})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "b", Id: 2, Seq: 1, Time: 1500})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1900})
+	if got := f.get("k"); got != "b" {
+		t.Fatalf("k=%q after a retry within the TTL, want b", got)
+	}
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 2100})
+	if got := f.get("k"); got != "a" {
+		t.Fatalf("k=%q after a retry of an expired session, want a", got)
+	}
+}
+
+// 会话包含在快照中，从快照恢复的副本同样能识别重复的请求
+func TestSessionSnapshot(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1000})
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "b", Id: 2, Seq: 1, Time: 1001})
+
+	g := makeStore(t, time.Minute)
+	g.apply(config.Op{Option: "Put", Key: "old", Value: "x", Id: 3, Seq: 1, Time: 900})
+	g.s.Restore(f.index, f.s.Snapshot())
+	g.index = f.index
+	if got := g.get("old"); got != "" {
+		t.Fatalf("old=%q survived Restore", got)
+	}
+	g.apply(config.Op{Option: "Put", Key: "k", Value: "a", Id: 1, Seq: 1, Time: 1002})
+	if got := g.get("k"); got != "b" {
+		t.Fatalf("k=%q after a retry on the restored store, want b", got)
+	}
+	g.apply(config.Op{Option: "Put", Key: "old", Value: "y", Id: 3, Seq: 1, Time: 1003})
+	if got := g.get("old"); got != "y" {
+		t.Fatalf("old=%q, session 3 should not be in the snapshot", got)
+	}
+}
+
+// 清理过期会话的时间是复制的状态：重启过的副本和没有重启的副本在同一条命令上清理，快照相同
+func TestSessionExpireDeterministic(t *testing.T) {
+	ops := []config.Op{
+		{Option: "Put", Key: "a", Value: "1", Id: 1, Seq: 1, Time: 1000},
+		{Option: "Put", Key: "b", Value: "2", Id: 2, Seq: 1, Time: 1200},
+		{Option: "Put", Key: "c", Value: "3", Id: 3, Seq: 1, Time: 2000},
+		{Option: "Put", Key: "c", Value: "4", Id: 3, Seq: 2, Time: 2300},
+	}
+	f, g := makeStore(t, time.Second), makeStore(t, time.Second)
+	for i, op := range ops {
+		if i == 3 {
+			// g在apply第4条命令之前重启
+			g.s = store.MakeStore(g.persister, time.Second)
+		}
+		f.apply(op)
+		g.apply(op)
+	}
+	if string(f.s.Snapshot()) != string(g.s.Snapshot()) {
+		t.Fatalf("a restarted replica expired sessions at a different command")
+	}
+}
+
+// 重启之后Raft从快照的位置重新apply日志：会话已经过期被删除，重放的日志也不能再次执行
+func TestApplyReplay(t *testing.T) {
+	f := makeStore(t, time.Second)
+	ops := []config.Op{
+		{Option: "Append", Key: "k", Value: "x", Id: 1, Seq: 1, Time: 1000},
+		{Option: "Put", Key: "other", Value: "y", Id: 2, Seq: 1, Time: 3000},
+	}
+	for _, op := range ops {
+		f.apply(op)
+	}
+
+	// 重启：同一个LevelDB上的新状态机
+	g := &fixture{t: t, persister: f.persister, s: store.MakeStore(f.persister, time.Second)}
+	if value, version, err := g.s.GetAt("k", 2); err != nil || value != "x" || version != 1 {
+		t.Fatalf("GetAt after restart returned %q, %d, %v", value, version, err)
+	}
+	for i, op := range ops {
+		if result := g.s.Apply(raft.ApplyMsg{CommandValid: true, Command: op.Encode(), CommandIndex: int32(i + 1), CommandTerm: 1}); result != nil {
+			t.Fatalf("replayed entry %d returned %+v", i+1, result)
+		}
+	}
+	g.index = f.index
+	if got := g.get("k"); got != "x" {
+		t.Fatalf("k=%q after replaying the log, want x", got)
+	}
+	g.apply(config.Op{Option: "Append", Key: "k", Value: "z", Id: 1, Seq: 2, Time: 3001})
+	if got := g.get("k"); got != "xz" {
+		t.Fatalf("k=%q after a new entry, want xz", got)
+	}
+}
@@ -0,0 +1,159 @@
+package storetest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/shardctrler"
+	"hckvstore/kvstore/store"
+	pst "hckvstore/persister"
+)
+
+func makeGroupStore(t *testing.T, gid int64) *fixture {
+	p := &pst.Persister{}
+	p.Init(t.TempDir())
+	t.Cleanup(p.Close)
+	return &fixture{t: t, persister: p, s: store.MakeGroupStore(p, time.Minute, gid)}
+}
+
+func (f *fixture) config(num int64, shards [shardctrler.NShards]int64) store.Reply {
+	data, _ := json.Marshal(shardctrler.Config{Num: num, Shards: shards})
+	return f.apply(config.Op{Option: store.OpConfig, Value: string(data)})
+}
+
+// 返回属于shard的一个key
+func keyIn(shard int) string {
+	for i := 0; ; i++ {
+		key := "key" + string(rune('a'+i))
+		if shardctrler.Key2Shard(key) == shard {
+			return key
+		}
+	}
+}
+
+func TestShardOwnership(t *testing.T) {
+	f := makeGroupStore(t, 1)
+	a, b := keyIn(0), keyIn(1)
+	if reply := f.apply(config.Op{Option: "Put", Key: a, Value: "1", Id: 7, Seq: 1}); reply.Err != shardctrler.ErrWrongGroup {
+		t.Fatalf("put before any config returned %+v", reply)
+	}
+
+	var shards [shardctrler.NShards]int64
+	shards[0], shards[1] = 1, 2
+	if reply := f.config(1, shards); reply.Version != 1 {
+		t.Fatalf("config 1 returned %+v", reply)
+	}
+	// 跳过的配置不apply
+	if reply := f.config(3, shards); reply.Version != 1 || f.s.Config().Num != 1 {
+		t.Fatalf("config 3 after config 1 returned %+v", reply)
+	}
+	if f.s.CheckKey(a) != "" || f.s.CheckKey(b) != shardctrler.ErrWrongGroup {
+		t.Fatalf("group 1 checks %q: %q, %q: %q", a, f.s.CheckKey(a), b, f.s.CheckKey(b))
+	}
+
+	// ErrWrongGroup不记录到会话中，之后同一个Seq的重试会执行
+	if reply := f.apply(config.Op{Option: "Put", Key: b, Value: "2", Id: 7, Seq: 1}); reply.Err != shardctrler.ErrWrongGroup {
+		t.Fatalf("put of a key of another group returned %+v", reply)
+	}
+	if reply := f.apply(config.Op{Option: "Put", Key: a, Value: "1", Id: 7, Seq: 1}); reply.Err != "" {
+		t.Fatalf("put of an owned key returned %+v", reply)
+	}
+	txn := config.Op{Option: store.OpTxn, Id: 7, Seq: 2, Success: []config.Op{{Option: "Put", Key: a, Value: "x"}, {Option: "Put", Key: b, Value: "y"}}}
+	if reply := f.apply(txn); reply.Err != shardctrler.ErrWrongGroup || f.get(a) != "1" {
+		t.Fatalf("txn across groups returned %+v", reply)
+	}
+
+	// Scan只返回本组负责的key
+	f.persister.Put(b, "stale")
+	kvs, _, _ := f.s.Scan(store.ScanOptions{})
+	if len(kvs) != 1 || kvs[0].Key != a {
+		t.Fatalf("scan returned %+v, want only %q", kvs, a)
+	}
+}
+
+// shard 0从组1迁移到组2：数据和去重表随shard迁移，两边确认之后才能apply下一个配置
+func TestShardMigration(t *testing.T) {
+	src, dst := makeGroupStore(t, 1), makeGroupStore(t, 2)
+	a := keyIn(0)
+	var shards [shardctrler.NShards]int64
+	for i := range shards {
+		shards[i] = 2
+	}
+	shards[0] = 1
+	src.config(1, shards)
+	dst.config(1, shards)
+	lease := src.apply(config.Op{Option: store.OpLeaseGrant, TTL: 60000, Time: 1000}).Lease
+	src.apply(config.Op{Option: "Put", Key: a, Value: "v1", Id: 7, Seq: 1, Lease: lease})
+
+	shards[0] = 2
+	src.config(2, shards)
+	dst.config(2, shards)
+	if got := src.s.CheckKey(a); got != shardctrler.ErrWrongGroup {
+		t.Fatalf("source checks the moved key: %q", got)
+	}
+	if reply := src.apply(config.Op{Option: "Put", Key: a, Value: "v2", Id: 8, Seq: 1}); reply.Err != shardctrler.ErrWrongGroup {
+		t.Fatalf("put of a frozen shard returned %+v", reply)
+	}
+	if reply := dst.apply(config.Op{Option: "Put", Key: a, Value: "v2", Id: 8, Seq: 1}); reply.Err != shardctrler.ErrShardNotReady {
+		t.Fatalf("put of a pulling shard returned %+v", reply)
+	}
+	pending := dst.s.PendingShards()
+	if len(pending) != 1 || !pending[0].Pull || pending[0].Shard != 0 || pending[0].Group != 1 || pending[0].Num != 2 {
+		t.Fatalf("pending shards of the target are %+v", pending)
+	}
+	if _, err := src.s.ExportShard(3, 0); err != shardctrler.ErrShardNotReady {
+		t.Fatalf("export at a config that is not applied returned %q", err)
+	}
+	data, err := src.s.ExportShard(2, 0)
+	if err != "" {
+		t.Fatalf("export failed: %q", err)
+	}
+	// 撤销lease不会修改已经冻结的数据
+	src.apply(config.Op{Option: store.OpLeaseRevoke, Lease: lease})
+	if data2, _ := src.s.ExportShard(2, 0); string(data2) != string(data) {
+		t.Fatalf("lease revocation changed the frozen shard")
+	}
+
+	// 跨组的Scan要求组apply的配置相同，并且没有正在拉取的shard
+	if _, _, err := dst.s.Scan(store.ScanOptions{ConfigNum: 2}); err == nil || err.Error() != shardctrler.ErrShardNotReady {
+		t.Fatalf("scan of a pulling group returned %v", err)
+	}
+	if _, _, err := src.s.Scan(store.ScanOptions{ConfigNum: 1}); err == nil || err.Error() != shardctrler.ErrWrongGroup {
+		t.Fatalf("scan at an old config returned %v", err)
+	}
+
+	install := config.Op{Option: store.OpInstallShard, Shard: 0, ConfigNum: 2, Value: string(data)}
+	dst.apply(install)
+	dst.apply(install)
+	if got := dst.get(a); got != "v1" || dst.s.CheckKey(a) != "" {
+		t.Fatalf("installed %q=%q, check %q", a, got, dst.s.CheckKey(a))
+	}
+	// 在源组执行过的请求重试到新的组时不会再次执行
+	if reply := dst.apply(config.Op{Option: "Put", Key: a, Value: "retry", Id: 7, Seq: 1}); reply.Err != "" || dst.get(a) != "v1" {
+		t.Fatalf("retried put returned %+v, %q=%q", reply, a, dst.get(a))
+	}
+	if reply := dst.apply(config.Op{Option: "Put", Key: a, Value: "v2", Id: 8, Seq: 1}); reply.Err != "" || dst.get(a) != "v2" {
+		t.Fatalf("put after the install returned %+v", reply)
+	}
+	if kvs, _, err := dst.s.Scan(store.ScanOptions{ConfigNum: 2}); err != nil || len(kvs) != 1 || kvs[0].Key != a {
+		t.Fatalf("scan after the install returned %+v, %v", kvs, err)
+	}
+
+	shards[1] = 1
+	if dst.config(3, shards); dst.s.Config().Num != 2 || !dst.s.Migrating() {
+		t.Fatalf("target applied config 3 before the source deleted the shard")
+	}
+	if pending := dst.s.PendingShards(); len(pending) != 1 || pending[0].Pull {
+		t.Fatalf("pending shards after the install are %+v", pending)
+	}
+	src.apply(config.Op{Option: store.OpDeleteShard, Shard: 0, ConfigNum: 2})
+	if src.s.Migrating() || src.get(a) != "" {
+		t.Fatalf("source still has the shard after the delete")
+	}
+	dst.apply(config.Op{Option: store.OpShardDeleted, Shard: 0, ConfigNum: 2})
+	if dst.config(3, shards); dst.s.Config().Num != 3 {
+		t.Fatalf("target did not apply config 3 after the migration")
+	}
+}
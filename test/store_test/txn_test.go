@@ -0,0 +1,120 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+)
+
+// 在两个账户之间转账：余额的版本没有变化时才写入两个新余额
+func TestTxnTransfer(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "alice", Value: "100", Id: 1, Seq: 1})
+	f.apply(config.Op{Option: "Put", Key: "bob", Value: "0", Id: 1, Seq: 2})
+	_, va := f.s.GetVersion("alice")
+	_, vb := f.s.GetVersion("bob")
+
+	transfer := config.Op{
+		Option: store.OpTxn,
+		Compares: []config.Compare{
+			{Key: "alice", Target: "Version", Result: "=", Version: va},
+			{Key: "bob", Target: "Version", Result: "=", Version: vb},
+		},
+		Success: []config.Op{
+			{Option: "Put", Key: "alice", Value: "70"},
+			{Option: "Put", Key: "bob", Value: "30"},
+		},
+		Failure: []config.Op{
+			{Option: "Get", Key: "alice"},
+			{Option: "Get", Key: "bob"},
+		},
+		Id:  2,
+		Seq: 1,
+	}
+	reply := f.apply(transfer)
+	if reply.Mismatch || len(reply.Results) != 2 || f.get("alice") != "70" || f.get("bob") != "30" {
+		t.Fatalf("transfer returned %+v, alice=%q bob=%q", reply, f.get("alice"), f.get("bob"))
+	}
+	if reply.Results[0].Version != int64(f.index) || reply.Results[1].Version != int64(f.index) {
+		t.Fatalf("writes of the txn at %d returned versions %+v", f.index, reply.Results)
+	}
+
+	// 版本已经变化，执行Failure中的读操作
+	transfer.Seq = 2
+	reply = f.apply(transfer)
+	if !reply.Mismatch || len(reply.Results) != 2 || reply.Results[0].Value != "70" || reply.Results[1].Value != "30" {
+		t.Fatalf("stale transfer returned %+v", reply)
+	}
+	if f.get("alice") != "70" || f.get("bob") != "30" {
+		t.Fatalf("stale transfer wrote alice=%q bob=%q", f.get("alice"), f.get("bob"))
+	}
+}
+
+// Txn中后面的操作读到前面的写入
+func TestTxnReadsOwnWrites(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	reply := f.apply(config.Op{
+		Option:   store.OpTxn,
+		Compares: []config.Compare{{Key: "k", Target: "Version", Result: "=", Version: 0}},
+		Success: []config.Op{
+			{Option: "Put", Key: "k", Value: "a"},
+			{Option: "Append", Key: "k", Value: "b"},
+			{Option: "Get", Key: "k"},
+			{Option: "Delete", Key: "gone"},
+			{Option: "Get", Key: "gone"},
+		},
+		Id:  1,
+		Seq: 1,
+	})
+	if reply.Mismatch || len(reply.Results) != 5 {
+		t.Fatalf("txn returned %+v", reply)
+	}
+	if got := reply.Results[2]; got.Value != "ab" || got.Version != int64(f.index) {
+		t.Fatalf("Get inside the txn returned %+v", got)
+	}
+	if got := reply.Results[4]; got.Value != "" || got.Version != 0 {
+		t.Fatalf("Get of a key deleted inside the txn returned %+v", got)
+	}
+	if f.get("k") != "ab" {
+		t.Fatalf("k=%q after the txn, want ab", f.get("k"))
+	}
+}
+
+func TestTxnCompare(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "m"})
+	for _, tc := range []struct {
+		c    config.Compare
+		want bool
+	}{
+		{config.Compare{Key: "k", Target: "Value", Result: "=", Value: "m"}, true},
+		{config.Compare{Key: "k", Target: "Value", Result: "!=", Value: "m"}, false},
+		{config.Compare{Key: "k", Target: "Value", Result: ">", Value: "a"}, true},
+		{config.Compare{Key: "k", Target: "Value", Result: "<", Value: "a"}, false},
+		{config.Compare{Key: "missing", Target: "Value", Result: "!=", Value: "m"}, false},
+		{config.Compare{Key: "missing", Target: "Version", Result: "=", Version: 0}, true},
+		{config.Compare{Key: "k", Target: "Version", Result: ">", Version: 0}, true},
+	} {
+		reply := f.apply(config.Op{Option: store.OpTxn, Compares: []config.Compare{tc.c}})
+		if reply.Mismatch == tc.want {
+			t.Errorf("compare %+v matched %v, want %v", tc.c, !reply.Mismatch, tc.want)
+		}
+	}
+}
+
+// 有不支持的操作的Txn不执行任何操作
+func TestTxnInvalid(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	reply := f.apply(config.Op{
+		Option: store.OpTxn,
+		Success: []config.Op{
+			{Option: "Put", Key: "k", Value: "a"},
+			{Option: store.OpTxn},
+		},
+	})
+	if reply.Err != store.ErrInvalidTxn || f.get("k") != "" {
+		t.Fatalf("invalid txn returned %+v, k=%q", reply, f.get("k"))
+	}
+}
@@ -0,0 +1,125 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hckvstore/config"
+	"hckvstore/kvstore/store"
+)
+
+func next(t *testing.T, w *store.Watcher) []store.Event {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	events, err := w.Next(ctx)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	return events
+}
+
+func TestWatchLive(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	w, revision, err := f.s.Watch("a/", true, 0)
+	if err != nil || revision != 0 {
+		t.Fatalf("Watch returned %v, %v", revision, err)
+	}
+	defer w.Close()
+	f.apply(config.Op{Option: "Put", Key: "a/1", Value: "x"})
+	f.apply(config.Op{Option: "Put", Key: "b", Value: "y"})
+	f.apply(config.Op{Option: "Delete", Key: "missing"})
+	f.apply(config.Op{Option: store.OpTxn, Success: []config.Op{
+		{Option: "Append", Key: "a/1", Value: "z"},
+		{Option: "Put", Key: "b", Value: "w"},
+		{Option: "Delete", Key: "a/1"},
+	}})
+
+	if got := next(t, w); len(got) != 1 || got[0] != (store.Event{Type: store.EventPut, Key: "a/1", Value: "x", Revision: 1}) {
+		t.Fatalf("first events %+v", got)
+	}
+	// 同一个Txn的事件一起返回，不包括不匹配的key
+	got := next(t, w)
+	want := []store.Event{
+		{Type: store.EventPut, Key: "a/1", Value: "xz", Revision: 4},
+		{Type: store.EventDelete, Key: "a/1", Revision: 4},
+	}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("txn events %+v, want %+v", got, want)
+	}
+}
+
+// 从历史中的revision恢复，先返回历史事件，再返回之后的修改
+func TestWatchResume(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	for _, v := range []string{"1", "2", "3"} {
+		f.apply(config.Op{Option: "Put", Key: "k", Value: v})
+	}
+	w, revision, err := f.s.Watch("k", false, 2)
+	if err != nil || revision != 3 {
+		t.Fatalf("Watch returned %v, %v", revision, err)
+	}
+	defer w.Close()
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "4"})
+	for _, want := range []string{"2", "3", "4"} {
+		if got := next(t, w); len(got) != 1 || got[0].Value != want {
+			t.Fatalf("events %+v, want value %s", got, want)
+		}
+	}
+
+	// 历史事件包含在快照中，从快照恢复的副本也可以从历史中恢复
+	g := makeStore(t, time.Minute)
+	g.s.Restore(f.index, f.s.Snapshot())
+	w2, _, err := g.s.Watch("k", false, 3)
+	if err != nil {
+		t.Fatalf("Watch on the restored store: %v", err)
+	}
+	defer w2.Close()
+	if got := next(t, w2); len(got) != 1 || got[0].Value != "3" {
+		t.Fatalf("events on the restored store %+v", got)
+	}
+}
+
+func TestWatchCompacted(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "old"})
+	// 下标跳到两倍的历史长度之后，之前的事件被压缩
+	f.index = 20000
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "new"})
+	_, _, err := f.s.Watch("k", false, 1)
+	if c, ok := err.(*store.CompactedError); !ok || c.Revision != 10001 {
+		t.Fatalf("Watch from a compacted revision returned %v", err)
+	}
+	w, _, err := f.s.Watch("k", false, 20001)
+	if err != nil {
+		t.Fatalf("Watch from a retained revision: %v", err)
+	}
+	defer w.Close()
+	if got := next(t, w); len(got) != 1 || got[0].Value != "new" {
+		t.Fatalf("events %+v", got)
+	}
+}
+
+// 安装快照和读取太慢都会取消watcher
+func TestWatchCanceled(t *testing.T) {
+	f := makeStore(t, time.Minute)
+	w, _, _ := f.s.Watch("k", false, 0)
+	f.apply(config.Op{Option: "Put", Key: "k", Value: "1"})
+	f.s.Restore(f.index, f.s.Snapshot())
+	next(t, w)
+	if _, err := w.Next(context.Background()); err != store.ErrWatchCanceled {
+		t.Fatalf("Next after Restore returned %v", err)
+	}
+
+	slow, _, _ := f.s.Watch("k", false, 0)
+	for i := 0; i < 1100; i++ {
+		f.apply(config.Op{Option: "Put", Key: "k", Value: "v"})
+	}
+	var err error
+	for err == nil {
+		_, err = slow.Next(context.Background())
+	}
+	if err != store.ErrWatchCanceled {
+		t.Fatalf("slow watcher returned %v", err)
+	}
+}